@@ -16,6 +16,7 @@ package core
 
 import (
 	"sport/core/model"
+	"sport/driven/notifications"
 	"sport/driven/provider/sidearm"
 	"sport/driven/storage"
 )
@@ -23,7 +24,7 @@ import (
 // Application structure
 type Application struct {
 	version  string
-	storage  storage.Adapter
+	storage  *storage.Adapter
 	provider Provider
 }
 
@@ -38,18 +39,28 @@ func (app *Application) GetSports() string {
 }
 
 // GetNews retrieves sport news
-func (app *Application) GetNews(id *string, sports []string, limit int) ([]model.News, error) {
-	return app.provider.GetNews(id, sports, limit)
+func (app *Application) GetNews(filter model.NewsFilter) (*model.NewsPage, error) {
+	return app.provider.GetNews(filter)
 }
 
-// GetCoaches retrieves the coaches for specific sport
-func (app *Application) GetCoaches(sport string) ([]model.Coach, error) {
-	return app.provider.GetCoaches(sport)
+// GetCoaches retrieves the coaches for specific sport and season
+func (app *Application) GetCoaches(sport string, year *int) ([]model.Coach, error) {
+	return app.provider.GetCoaches(sport, year)
 }
 
-// GetPlayers retrieves the players for specific sport
-func (app *Application) GetPlayers(sport string) ([]model.Player, error) {
-	return app.provider.GetPlayers(sport)
+// GetPlayers retrieves the players for specific sport and season
+func (app *Application) GetPlayers(sport string, year *int, filter model.PlayersFilter) ([]model.Player, error) {
+	return app.provider.GetPlayers(sport, year, filter)
+}
+
+// GetPlayer retrieves the full profile of a player
+func (app *Application) GetPlayer(sport string, year *int, id string) (*model.Player, error) {
+	return app.provider.GetPlayer(sport, year, id)
+}
+
+// GetCoach retrieves the full profile of a coach
+func (app *Application) GetCoach(sport string, year *int, id string) (*model.Coach, error) {
+	return app.provider.GetCoach(sport, year, id)
 }
 
 // GetSocialNetworks retrieves the social accounts
@@ -58,8 +69,8 @@ func (app *Application) GetSocialNetworks() ([]model.SportSocial, error) {
 }
 
 // GetGames retrieves games based on selected filters
-func (app *Application) GetGames(sports []string, id *string, startDate *string, endDate *string, limit int) ([]model.Game, error) {
-	return app.provider.GetGames(sports, id, startDate, endDate, limit)
+func (app *Application) GetGames(filter model.GamesFilter) ([]model.Game, error) {
+	return app.provider.GetGames(filter)
 }
 
 // GetTeamSchedule retrieves the schedule for sport in a specific year
@@ -72,11 +83,36 @@ func (app *Application) GetTeamRecord(sport string, year *int) (*model.Record, e
 	return app.provider.GetTeamRecord(sport, year)
 }
 
+// GetTeamHistory retrieves all seasons of the sport with their records
+func (app *Application) GetTeamHistory(sport string) ([]model.TeamSeason, error) {
+	return app.provider.GetTeamHistory(sport)
+}
+
+// GetHeadToHead retrieves the summary of the games against an opponent across the seasons
+func (app *Application) GetHeadToHead(sport string, opponentID int, limit int) (*model.HeadToHead, error) {
+	return app.provider.GetHeadToHead(sport, opponentID, limit)
+}
+
+// GetStandings retrieves the conference standings of the sport
+func (app *Application) GetStandings(sport string) (*model.Standings, error) {
+	return app.provider.GetStandings(sport)
+}
+
 // GetLiveGames retrieves details for current live games
 func (app *Application) GetLiveGames() ([]model.LiveGame, error) {
 	return app.provider.GetLiveGames()
 }
 
+// GetBoxScore retrieves the live or final box score of a game
+func (app *Application) GetBoxScore(gameID int) (*model.GameBoxScore, error) {
+	return app.provider.GetBoxScore(gameID)
+}
+
+// GetPlays retrieves the play by play of a game after the since sequence number
+func (app *Application) GetPlays(gameID int, since int) (*model.GamePlays, error) {
+	return app.provider.GetPlays(gameID, since)
+}
+
 // GetConfig retrieves provider's config
 func (app *Application) GetConfig() (map[string]interface{}, error) {
 	return app.provider.GetConfig()
@@ -87,13 +123,34 @@ func (app *Application) UpdateConfig(cfgBytes []byte) error {
 	return app.provider.UpdateConfig(cfgBytes)
 }
 
+// GetNotificationsOutbox retrieves the pending and the dead-lettered notifications
+func (app *Application) GetNotificationsOutbox() (*model.NotificationsOutbox, error) {
+	return app.provider.GetNotificationsOutbox()
+}
+
+// GetSourceHealthEvents retrieves the latest health events of the live stats sources
+func (app *Application) GetSourceHealthEvents() ([]model.SourceHealthEvent, error) {
+	return app.provider.GetSourceHealthEvents()
+}
+
+// ReplayNotifications sends again the dead-lettered notifications
+func (app *Application) ReplayNotifications(id *string) (int, error) {
+	return app.provider.ReplayNotifications(id)
+}
+
+// PreviewNotification renders a notification message template
+func (app *Application) PreviewNotification(preview model.NotificationPreview) (string, error) {
+	return app.provider.PreviewNotification(preview)
+}
+
 // NewApplication creates new Application instance
 func NewApplication(version string, internalAPIKey string, appID string, orgID string, host string, ftpHost string, ftpUser string, ftpPassword string) *Application {
 	sa := storage.NewStorageAdapter()
+	rokwire := notifications.New(internalAPIKey, host, appID, orgID)
 
 	// Here we define current sport provider!
-	sp := sidearm.NewProvider(internalAPIKey, host, ftpHost, ftpUser, ftpPassword, appID, orgID)
+	sp := sidearm.NewProvider(rokwire, ftpHost, ftpUser, ftpPassword, sa)
 	sp.Start()
 
-	return &Application{version: version, storage: *sa, provider: sp}
+	return &Application{version: version, storage: sa, provider: sp}
 }
@@ -31,14 +31,25 @@ type Recipient struct {
 
 // Provider interface has to be implemented by all sports providers
 type Provider interface {
-	GetNews(id *string, sport []string, limit int) ([]model.News, error)
-	GetCoaches(sport string) ([]model.Coach, error)
-	GetPlayers(sport string) ([]model.Player, error)
+	GetNews(filter model.NewsFilter) (*model.NewsPage, error)
+	GetCoaches(sport string, year *int) ([]model.Coach, error)
+	GetPlayers(sport string, year *int, filter model.PlayersFilter) ([]model.Player, error)
+	GetPlayer(sport string, year *int, id string) (*model.Player, error)
+	GetCoach(sport string, year *int, id string) (*model.Coach, error)
 	GetSocialNetworks() ([]model.SportSocial, error)
-	GetGames(sports []string, id *string, startDate *string, endDate *string, limit int) ([]model.Game, error)
+	GetGames(filter model.GamesFilter) ([]model.Game, error)
 	GetTeamSchedule(sport string, year *int) (*model.Schedule, error)
 	GetTeamRecord(sport string, year *int) (*model.Record, error)
+	GetTeamHistory(sport string) ([]model.TeamSeason, error)
+	GetHeadToHead(sport string, opponentID int, limit int) (*model.HeadToHead, error)
+	GetStandings(sport string) (*model.Standings, error)
 	GetLiveGames() ([]model.LiveGame, error)
+	GetBoxScore(gameID int) (*model.GameBoxScore, error)
+	GetPlays(gameID int, since int) (*model.GamePlays, error)
 	GetConfig() (map[string]interface{}, error)
 	UpdateConfig(data []byte) error
+	GetNotificationsOutbox() (*model.NotificationsOutbox, error)
+	GetSourceHealthEvents() ([]model.SourceHealthEvent, error)
+	ReplayNotifications(id *string) (int, error)
+	PreviewNotification(preview model.NotificationPreview) (string, error)
 }
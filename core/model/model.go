@@ -14,6 +14,11 @@
 
 package model
 
+import (
+	"fmt"
+	"time"
+)
+
 // News structure
 type News struct {
 	ID          string `json:"id"`
@@ -26,6 +31,59 @@ type News struct {
 	FullTextRaw string `json:"fulltext_raw"`
 	ImageURL    string `json:"image_url"`
 	PubDateUtc  string `json:"pub_date_utc"`
+
+	Excerpt            string `json:"excerpt"`
+	ReadingTimeMinutes int    `json:"reading_time_minutes"`
+}
+
+// newsTimeLayouts are the formats of the publish time of the news
+var newsTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+// ParseNewsTime parses the publish time of a story like PubDateUtc, the time is in UTC
+func ParseNewsTime(value string) (time.Time, error) {
+	for _, layout := range newsTimeLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid publish time %s", value)
+}
+
+// The formats of the news full text
+const (
+	NewsFormatHTML     string = "html"
+	NewsFormatText     string = "text"
+	NewsFormatMarkdown string = "markdown"
+)
+
+// NewsFilter contains the filters and the page of the news
+type NewsFilter struct {
+	ID         *string
+	Sports     []string
+	Categories []string
+	Query      string // all words must be in the title, the description or the full text
+	Since      *time.Time
+	Until      *time.Time
+	Offset     int
+	Cursor     *NewsCursor // the page starts after the news of the cursor, it is used instead of the offset
+	Limit      int
+	Format     string // the format of the full text - html (default), text or markdown
+}
+
+// NewsCursor points to a news in the list ordered by the publish time
+type NewsCursor struct {
+	PubDateUtc string `json:"p"`
+	ID         string `json:"i"`
+}
+
+// NewsPage is a page of the filtered news
+type NewsPage struct {
+	News  []News
+	Total int         // the count of all news which match the filters
+	Next  *NewsCursor // nil for the last page
+	// false if there are more news than the max loaded ones, then Total counts only the loaded news
+	TotalExact bool
 }
 
 // Coach structure
@@ -38,6 +96,7 @@ type Coach struct {
 	Phone     string  `json:"phone,omitempty"`
 	Title     string  `json:"title,omitempty"`
 	Bio       string  `json:"bio,omitempty"`
+	BioLink   string  `json:"bio_link,omitempty"`
 	Photos    *Photos `json:"photos,omitempty"`
 }
 
@@ -58,8 +117,48 @@ type Player struct {
 	Captain    bool    `json:"captain,omitempty"`
 	Bio        string  `json:"bio,omitempty"`
 	Photos     *Photos `json:"photos,omitempty"`
+
+	Uni2           string        `json:"uni_2,omitempty"`
+	PosLong        string        `json:"pos_long,omitempty"`
+	Positions      []string      `json:"positions,omitempty"`
+	HeightFeet     int           `json:"height_feet,omitempty"`
+	HeightInches   int           `json:"height_inches,omitempty"`
+	Year           string        `json:"year,omitempty"`
+	Major          string        `json:"major,omitempty"`
+	PreviousSchool string        `json:"previous_school,omitempty"`
+	BioLink        string        `json:"bio_link,omitempty"`
+	ShopURL        string        `json:"shop_url,omitempty"`
+	Social         *PlayerSocial `json:"social,omitempty"`
+}
+
+// PlayerSocial contains the social network user names of a player
+type PlayerSocial struct {
+	Facebook  string `json:"facebook,omitempty"`
+	Instagram string `json:"instagram,omitempty"`
+	Twitter   string `json:"twitter,omitempty"`
+	Snapchat  string `json:"snapchat,omitempty"`
+	TikTok    string `json:"tiktok,omitempty"`
+	Cameo     string `json:"cameo,omitempty"`
+	YouTube   string `json:"youtube,omitempty"`
+	Twitch    string `json:"twitch,omitempty"`
 }
 
+// PlayersFilter contains the filters and the order of the players
+type PlayersFilter struct {
+	Position string // the short position, for example QB
+	Class    string // the class year, for example Jr. or Junior
+	Number   string // the jersey number
+	Sort     string // name, number, position or class, the Sidearm order if empty
+}
+
+// The orders of the players
+const (
+	PlayersSortName     string = "name"
+	PlayersSortNumber   string = "number"
+	PlayersSortPosition string = "position"
+	PlayersSortClass    string = "class"
+)
+
 // Photos structure
 type Photos struct {
 	Fullsize  string `json:"fullsize,omitempty"`
@@ -92,6 +191,19 @@ type Game struct {
 	Links          *Links    `json:"links,omitempty"`
 	Opponent       *Opponent `json:"opponent,omitempty"`
 	Results        *[]Result `json:"results,omitempty"`
+
+	StartTime *time.Time `json:"start_time,omitempty"` // RFC 3339 start of the game, nil if it is not known
+	EndTime   *time.Time `json:"end_time,omitempty"`   // RFC 3339 end of the game, nil if it is not known
+}
+
+// GamesFilter contains the filters of the games
+type GamesFilter struct {
+	Sports []string
+	ID     *string
+	Start  *time.Time // the games which start before are skipped, it is the current day if not set and Past is false
+	End    *time.Time // the games which start at or after are skipped
+	Past   bool       // the games before the current day are included when there is no Start
+	Limit  int
 }
 
 // Sport structure
@@ -108,11 +220,27 @@ type Location struct {
 
 // Links structure
 type Links struct {
-	Livestats string    `json:"livestats,omitempty"`
-	Video     string    `json:"video,omitempty"`
-	Audio     string    `json:"audio,omitempty"`
-	Tickets   string    `json:"tickets,omitempty"`
-	PreGame   *GameInfo `json:"pregame,omitempty"`
+	Livestats string     `json:"livestats,omitempty"`
+	Video     string     `json:"video,omitempty"`
+	Audio     string     `json:"audio,omitempty"`
+	Tickets   string     `json:"tickets,omitempty"`
+	Notes     string     `json:"notes,omitempty"`
+	PreGame   *GameInfo  `json:"pregame,omitempty"`
+	PostGame  *GameInfo  `json:"postgame,omitempty"`
+	BoxScore  *BoxScore  `json:"boxscore,omitempty"`
+	GameFiles []GameFile `json:"gamefiles,omitempty"`
+}
+
+// BoxScore structure
+type BoxScore struct {
+	URL  string `json:"url,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// GameFile structure
+type GameFile struct {
+	Link  string `json:"link,omitempty"`
+	Title string `json:"title,omitempty"`
 }
 
 // GameInfo structure
@@ -125,6 +253,7 @@ type GameInfo struct {
 
 // Opponent structure
 type Opponent struct {
+	GlobalID  int    `json:"global_id,omitempty"`
 	Name      string `json:"name,omitempty"`
 	LogoImage string `json:"logo_image,omitempty"`
 }
@@ -150,6 +279,177 @@ type Record struct {
 	HomeRecord       string `json:"home_record,omitempty"`
 	AwayRecord       string `json:"away_record,omitempty"`
 	NeutralRecord    string `json:"neutral_record,omitempty"`
+
+	Overall        *WinLoss `json:"overall,omitempty"`
+	Conference     *WinLoss `json:"conference,omitempty"`
+	Home           *WinLoss `json:"home,omitempty"`
+	Away           *WinLoss `json:"away,omitempty"`
+	Neutral        *WinLoss `json:"neutral,omitempty"`
+	CurrentStreak  *Streak  `json:"current_streak,omitempty"`
+	ConferenceRank *Rank    `json:"conference_rank,omitempty"`
+	Computed       bool     `json:"computed,omitempty"` // true if the record is computed from the game results
+}
+
+// WinLoss is a parsed record like "12-3-1"
+type WinLoss struct {
+	Wins       int     `json:"wins"`
+	Losses     int     `json:"losses"`
+	Ties       int     `json:"ties"`
+	Percentage float64 `json:"percentage"` // the ties count as half a win
+}
+
+// Streak is a parsed streak like "W3"
+type Streak struct {
+	Type   string `json:"type"` // W, L or T
+	Length int    `json:"length"`
+}
+
+// Rank is a position in the standings
+type Rank struct {
+	Position int  `json:"position"`
+	Tied     bool `json:"tied,omitempty"`
+}
+
+// TeamSeason is a season of a team with its record
+type TeamSeason struct {
+	Year    string  `json:"year"`
+	Label   string  `json:"label"`
+	Current bool    `json:"current"`
+	Record  *Record `json:"record,omitempty"`
+}
+
+// HeadToHead is the summary of the games against an opponent across the seasons
+type HeadToHead struct {
+	Sport        string    `json:"sport"`
+	Opponent     *Opponent `json:"opponent,omitempty"`
+	Games        int       `json:"games"`
+	Wins         int       `json:"wins"`
+	Losses       int       `json:"losses"`
+	Ties         int       `json:"ties"`
+	Streak       string    `json:"streak,omitempty"` // for example W3, the current streak against the opponent
+	LastMeetings []Game    `json:"last_meetings"`    // the last final games against the opponent, the most recent first
+}
+
+// Standings are the conference standings of a sport
+type Standings struct {
+	Sport           string          `json:"sport"`
+	ConferenceID    string          `json:"conference_id,omitempty"`
+	ConferenceTitle string          `json:"conference_title,omitempty"`
+	Division        string          `json:"division,omitempty"`
+	UpdatedAt       *time.Time      `json:"updated_at,omitempty"`
+	Teams           []StandingsTeam `json:"teams"`
+}
+
+// StandingsTeam is a team in the standings
+type StandingsTeam struct {
+	Rank             Rank     `json:"rank"`
+	Name             string   `json:"name"`
+	GlobalID         int      `json:"global_id,omitempty"`
+	Division         string   `json:"division,omitempty"`
+	ConferenceRecord string   `json:"conference_record,omitempty"`
+	Conference       *WinLoss `json:"conference,omitempty"`
+	OverallRecord    string   `json:"overall_record,omitempty"`
+	Overall          *WinLoss `json:"overall,omitempty"`
+	Streak           string   `json:"streak,omitempty"`
+	CurrentStreak    *Streak  `json:"current_streak,omitempty"`
+	OurTeam          bool     `json:"our_team,omitempty"`
+}
+
+// GameBoxScore is the box score of a game from the stat crew live stats
+type GameBoxScore struct {
+	GameID     string         `json:"game_id"`
+	Sport      string         `json:"sport"`
+	IsComplete bool           `json:"is_complete"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	Teams      []BoxScoreTeam `json:"teams"`
+}
+
+// BoxScoreTeam is the box score of a team in a game
+type BoxScoreTeam struct {
+	Code    string           `json:"code,omitempty"`
+	Name    string           `json:"name"`
+	Home    bool             `json:"home"`
+	OurTeam bool             `json:"our_team,omitempty"`
+	Totals  BoxScoreStats    `json:"totals"`
+	Players []BoxScorePlayer `json:"players"`
+}
+
+// BoxScorePlayer is the box score of a player in a game
+type BoxScorePlayer struct {
+	RosterID string        `json:"roster_id,omitempty"` // the id of the player in the roster, only for our team
+	Uni      string        `json:"uni,omitempty"`
+	Name     string        `json:"name"`
+	Position string        `json:"position,omitempty"`
+	Starter  bool          `json:"starter,omitempty"`
+	Stats    BoxScoreStats `json:"stats"`
+}
+
+// BoxScoreStats are the stats by category as they come from the stat crew, for example {"rush": {"att": "12", "yds": "87"}}
+type BoxScoreStats map[string]map[string]string
+
+// GamePlays is the play by play of a game from the stat crew live stats
+type GamePlays struct {
+	GameID     string    `json:"game_id"`
+	Sport      string    `json:"sport"`
+	IsComplete bool      `json:"is_complete"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	LastSeq    int       `json:"last_seq"` // the sequence number of the last play, it is the since parameter for the next request
+	Plays      []Play    `json:"plays"`
+}
+
+// Play is a play of a game. The sequence number of a play does not change, the new plays get higher numbers even if
+// the stat crew inserts them between the older plays.
+type Play struct {
+	Seq           int    `json:"seq"`
+	Period        int    `json:"period"`
+	Clock         string `json:"clock,omitempty"`
+	Home          *bool  `json:"home,omitempty"` // the team of the play, nil if the play is not for a team
+	Player        string `json:"player,omitempty"`
+	Uni           string `json:"uni,omitempty"`
+	Action        string `json:"action,omitempty"`
+	Type          string `json:"type,omitempty"`
+	Text          string `json:"text"`
+	Scoring       bool   `json:"scoring,omitempty"`
+	HomeScore     *int   `json:"home_score,omitempty"`
+	VisitingScore *int   `json:"visiting_score,omitempty"`
+}
+
+// NotificationPreview is a request for rendering a notification message template
+type NotificationPreview struct {
+	Template string `json:"template"` // the template to render, the configured message with the key is rendered if it is empty
+	Key      string `json:"key"`
+	Locale   string `json:"locale"`
+	GameID   string `json:"game_id"` // the sample data is used if it is empty
+}
+
+// SourceHealthEvent is a change of the health of a live stats source
+type SourceHealthEvent struct {
+	GameID  string    `json:"game_id"`
+	Sport   string    `json:"sport"`
+	Source  string    `json:"source"`
+	Type    string    `json:"type"` // stale, recovered, failover, failback, disagreement or agreement
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// NotificationsOutbox structure
+type NotificationsOutbox struct {
+	Pending     []OutboxMessage `json:"pending"`
+	DeadLetters []OutboxMessage `json:"dead_letters"`
+}
+
+// OutboxMessage structure
+type OutboxMessage struct {
+	ID          string            `json:"id"`
+	Type        string            `json:"type"` // notification or data
+	Topic       string            `json:"topic"`
+	Title       string            `json:"title,omitempty"`
+	Body        string            `json:"body,omitempty"`
+	Data        map[string]string `json:"data,omitempty"`
+	Attempts    int               `json:"attempts"`
+	LastError   string            `json:"last_error,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	NextAttempt time.Time         `json:"next_attempt"`
 }
 
 // LiveGame interface
@@ -0,0 +1,475 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sentKeysState   string = "notification_keys"
+	deferredState   string = "notification_deferred"
+	sentKeysMaxAge         = time.Hour * 24 * 14
+	defaultRateKey  string = "default"
+	quietTimeLayout string = "15:04"
+)
+
+// Storage is used for persisting the state of the notifications
+type Storage interface {
+	LoadState(name string, value interface{}) error
+	SaveState(name string, value interface{}) error
+}
+
+// Sender sends the notification and the data messages
+type Sender interface {
+	SendNotification(msg Message) error
+	SendData(topic string, data map[string]string) error
+}
+
+// DispatcherConfig structure
+type DispatcherConfig struct {
+	RateLimits      map[string]int   `json:"rate_limits"`      // min seconds between notifications per topic, the key is the last topic segment or "default". The messages within the window are delayed.
+	CoalesceSeconds int              `json:"coalesce_seconds"` // data messages for the same game within this window after a sent one are merged into the latest one
+	QuietHours      QuietHoursConfig `json:"quiet_hours"`
+}
+
+// QuietHoursConfig structure
+type QuietHoursConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start"` // 22:00
+	End      string `json:"end"`   // 07:00
+	TimeZone string `json:"time_zone"`
+}
+
+// Message structure
+type Message struct {
+	Topic  string            `json:"topic"`
+	Title  string            `json:"title"`
+	Body   string            `json:"body"`
+	Data   map[string]string `json:"data"`
+	Key    string            `json:"key"`    // idempotency key - a message with the same key is sent only once
+	Live   bool              `json:"live"`   // live game messages are not delayed by the quiet hours
+	Locale string            `json:"locale"` // the message is sent to the topic tagged with the locale, empty for the default locale
+	// the sender limits these messages itself, so the rate limit of the topic is not applied
+	Unthrottled bool `json:"unthrottled"`
+}
+
+// deferredData contains the messages which wait for the end of the quiet hours or the rate limit window
+type deferredData struct {
+	QuietHours []Message            `json:"quiet_hours"`
+	Throttled  map[string][]Message `json:"throttled"` // by the topic with the locale in the order of sending
+}
+
+// Dispatcher throttles, deduplicates and schedules the messages before sending them
+type Dispatcher struct {
+	mu       sync.Mutex
+	outbox   *Outbox
+	storage  Storage
+	config   DispatcherConfig
+	sentKeys map[string]time.Time
+	lastSent map[string]time.Time
+	// the keys of the deferred messages, so the same message is deferred only once
+	pendingKeys map[string]bool
+	// the coalescing windows by the topic and the game with the latest data message within the window, nil if there is none
+	pendingData map[string]*Message
+	deferred    deferredData
+	deferTimer  *time.Timer
+	// the timers which send the next throttled message of the topic
+	throttleTimers map[string]*time.Timer
+}
+
+// NewDispatcher creates new dispatcher instance
+func NewDispatcher(outbox *Outbox, storage Storage, config DispatcherConfig) *Dispatcher {
+	sentKeys := make(map[string]time.Time)
+	if storage != nil {
+		err := storage.LoadState(sentKeysState, &sentKeys)
+		if err != nil {
+			log.Printf("dispatcher -> NewDispatcher: failed to load sent keys. Reason: %s", err.Error())
+		}
+	}
+	var deferred deferredData
+	if storage != nil {
+		err := storage.LoadState(deferredState, &deferred)
+		if err != nil {
+			log.Printf("dispatcher -> NewDispatcher: failed to load deferred messages. Reason: %s", err.Error())
+		}
+	}
+	if deferred.Throttled == nil {
+		deferred.Throttled = make(map[string][]Message)
+	}
+
+	//the copies of the same message which were deferred before the restart are dropped
+	pendingKeys := make(map[string]bool)
+	deferred.QuietHours = dropPendingCopies(deferred.QuietHours, pendingKeys)
+	for topic, queue := range deferred.Throttled {
+		deferred.Throttled[topic] = dropPendingCopies(queue, pendingKeys)
+	}
+
+	d := &Dispatcher{outbox: outbox, storage: storage, config: config, sentKeys: sentKeys, lastSent: make(map[string]time.Time),
+		pendingKeys: pendingKeys, pendingData: make(map[string]*Message), deferred: deferred, throttleTimers: make(map[string]*time.Timer)}
+
+	//continue with the messages which were deferred before the restart
+	d.mu.Lock()
+	if len(deferred.QuietHours) > 0 {
+		log.Printf("dispatcher -> NewDispatcher: %d messages delayed for the quiet hours loaded", len(deferred.QuietHours))
+		var after time.Duration
+		if quietEnd := d.quietHoursEnd(time.Now()); !quietEnd.IsZero() {
+			after = time.Until(quietEnd)
+		}
+		d.scheduleDeferred(after)
+	}
+	for topic := range deferred.Throttled {
+		d.scheduleThrottled(topic, 0)
+	}
+	d.mu.Unlock()
+	return d
+}
+
+// UpdateConfig updates the dispatcher config
+func (d *Dispatcher) UpdateConfig(config DispatcherConfig) {
+	d.mu.Lock()
+	d.config = config
+	d.mu.Unlock()
+	log.Println("dispatcher -> UpdateConfig: config updated")
+}
+
+// SendNotification sends notification message unless it was already sent or deferred, it is throttled or it is delayed for the quiet hours
+func (d *Dispatcher) SendNotification(msg Message) error {
+	topic, key := getLocaleTopicAndKey(msg)
+
+	d.mu.Lock()
+	if len(key) > 0 {
+		if _, sent := d.sentKeys[key]; sent {
+			d.mu.Unlock()
+			log.Printf("dispatcher -> SendNotification: skip already sent message key:%s topic:%s", key, topic)
+			return nil
+		}
+		if d.pendingKeys[key] {
+			d.mu.Unlock()
+			log.Printf("dispatcher -> SendNotification: skip already deferred message key:%s topic:%s", key, topic)
+			return nil
+		}
+	}
+
+	now := time.Now()
+	if !msg.Live {
+		if quietEnd := d.quietHoursEnd(now); !quietEnd.IsZero() {
+			d.deferred.QuietHours = append(d.deferred.QuietHours, msg)
+			d.setPending(key, true)
+			d.scheduleDeferred(quietEnd.Sub(now))
+			deferred := d.copyDeferred()
+			d.mu.Unlock()
+			log.Printf("dispatcher -> SendNotification: delay message for the quiet hours until %s topic:%s", quietEnd, topic)
+			d.saveDeferred(deferred)
+			return nil
+		}
+	}
+
+	//the rate limit is for the kind of the topic without the locale. The throttled messages are sent after the window
+	//in their order, so a new message waits for the throttled ones before it.
+	limit := d.rateLimit(msg.Topic)
+	last, exists := d.lastSent[topic]
+	if !msg.Unthrottled && (len(d.deferred.Throttled[topic]) > 0 || (exists && now.Sub(last) < limit)) {
+		d.throttle(topic, msg, last.Add(limit).Sub(now))
+		deferred := d.copyDeferred()
+		d.mu.Unlock()
+		log.Printf("dispatcher -> SendNotification: throttle message until the end of the window topic:%s title:%s", topic, msg.Title)
+		d.saveDeferred(deferred)
+		return nil
+	}
+	d.lastSent[topic] = now
+	d.mu.Unlock()
+
+	d.outbox.EnqueueNotification(topic, msg.Title, msg.Body, msg.Data)
+	d.markSent(key, now)
+	return nil
+}
+
+// SendData sends data message. The first update for the topic and game is sent at once, the rapid updates after it
+// are coalesced into the latest one which is sent at the end of the window.
+func (d *Dispatcher) SendData(topic string, data map[string]string) error {
+	d.mu.Lock()
+	window := time.Duration(d.config.CoalesceSeconds) * time.Second
+	if window <= 0 {
+		d.mu.Unlock()
+		d.outbox.EnqueueData(topic, data)
+		return nil
+	}
+
+	coalesceKey := fmt.Sprintf("%s|%s", topic, data["GameId"])
+	if _, open := d.pendingData[coalesceKey]; open {
+		d.pendingData[coalesceKey] = &Message{Topic: topic, Data: data}
+		d.mu.Unlock()
+		return nil
+	}
+	d.pendingData[coalesceKey] = nil
+	time.AfterFunc(window, func() { d.flushData(coalesceKey) })
+	d.mu.Unlock()
+
+	d.outbox.EnqueueData(topic, data)
+	return nil
+}
+
+// flushData sends the latest data message of the window and opens the next window for it, the window is closed if there
+// were no updates within it
+func (d *Dispatcher) flushData(coalesceKey string) {
+	d.mu.Lock()
+	msg := d.pendingData[coalesceKey]
+	if msg == nil {
+		delete(d.pendingData, coalesceKey)
+		d.mu.Unlock()
+		return
+	}
+	d.pendingData[coalesceKey] = nil
+	window := time.Duration(d.config.CoalesceSeconds) * time.Second
+	time.AfterFunc(window, func() { d.flushData(coalesceKey) })
+	d.mu.Unlock()
+
+	d.outbox.EnqueueData(msg.Topic, msg.Data)
+}
+
+// scheduleDeferred sends the delayed messages after the quiet hours end. It must be called under lock.
+func (d *Dispatcher) scheduleDeferred(after time.Duration) {
+	if d.deferTimer != nil {
+		return
+	}
+	d.deferTimer = time.AfterFunc(after, func() {
+		d.mu.Lock()
+		messages := d.deferred.QuietHours
+		d.deferred.QuietHours = nil
+		d.deferTimer = nil
+		//the messages are sent again, so they are not deferred anymore
+		for _, msg := range messages {
+			_, key := getLocaleTopicAndKey(msg)
+			d.setPending(key, false)
+		}
+		deferred := d.copyDeferred()
+		d.mu.Unlock()
+		d.saveDeferred(deferred)
+
+		log.Printf("dispatcher -> scheduleDeferred: the quiet hours ended, send %d delayed messages", len(messages))
+		for _, msg := range messages {
+			err := d.SendNotification(msg)
+			if err != nil {
+				log.Printf("dispatcher -> scheduleDeferred: error sending message topic:%s %s", msg.Topic, err.Error())
+			}
+		}
+	})
+}
+
+// throttle adds the message to the throttled messages of the topic. It must be called under lock.
+func (d *Dispatcher) throttle(topic string, msg Message, after time.Duration) {
+	queue := d.deferred.Throttled[topic]
+	if msg.Live && len(queue) > 0 && queue[len(queue)-1].Live {
+		//the live alerts describe the current state of the game, so only the latest one is worth sending
+		_, replacedKey := getLocaleTopicAndKey(queue[len(queue)-1])
+		d.setPending(replacedKey, false)
+		queue[len(queue)-1] = msg
+	} else {
+		queue = append(queue, msg)
+	}
+	_, key := getLocaleTopicAndKey(msg)
+	d.setPending(key, true)
+	d.deferred.Throttled[topic] = queue
+	d.scheduleThrottled(topic, after)
+}
+
+// scheduleThrottled sends the next throttled message of the topic after the duration. It must be called under lock.
+func (d *Dispatcher) scheduleThrottled(topic string, after time.Duration) {
+	if _, scheduled := d.throttleTimers[topic]; scheduled {
+		return
+	}
+	if after < 0 {
+		after = 0
+	}
+	d.throttleTimers[topic] = time.AfterFunc(after, func() { d.flushThrottled(topic) })
+}
+
+// flushThrottled sends the first throttled message of the topic and schedules the next one after the rate limit
+func (d *Dispatcher) flushThrottled(topic string) {
+	d.mu.Lock()
+	delete(d.throttleTimers, topic)
+	queue := d.deferred.Throttled[topic]
+	if len(queue) == 0 {
+		d.mu.Unlock()
+		return
+	}
+	msg := queue[0]
+	if len(queue) == 1 {
+		delete(d.deferred.Throttled, topic)
+	} else {
+		d.deferred.Throttled[topic] = queue[1:]
+		d.scheduleThrottled(topic, d.rateLimit(msg.Topic))
+	}
+	_, key := getLocaleTopicAndKey(msg)
+	d.setPending(key, false)
+	_, sent := d.sentKeys[key]
+	now := time.Now()
+	if !sent {
+		d.lastSent[topic] = now
+	}
+	deferred := d.copyDeferred()
+	d.mu.Unlock()
+	d.saveDeferred(deferred)
+
+	if sent {
+		log.Printf("dispatcher -> flushThrottled: skip already sent message key:%s topic:%s", key, topic)
+		return
+	}
+	log.Printf("dispatcher -> flushThrottled: send throttled message topic:%s title:%s", topic, msg.Title)
+	d.outbox.EnqueueNotification(topic, msg.Title, msg.Body, msg.Data)
+	d.markSent(key, now)
+}
+
+// setPending marks the key of the message as deferred or not. It must be called under lock.
+func (d *Dispatcher) setPending(key string, pending bool) {
+	if len(key) == 0 {
+		return
+	}
+	if pending {
+		d.pendingKeys[key] = true
+	} else {
+		delete(d.pendingKeys, key)
+	}
+}
+
+// dropPendingCopies removes the messages with the keys which are already pending and adds the keys of the others
+func dropPendingCopies(messages []Message, pendingKeys map[string]bool) []Message {
+	var result []Message
+	for _, msg := range messages {
+		_, key := getLocaleTopicAndKey(msg)
+		if len(key) > 0 && pendingKeys[key] {
+			continue
+		}
+		if len(key) > 0 {
+			pendingKeys[key] = true
+		}
+		result = append(result, msg)
+	}
+	return result
+}
+
+// copyDeferred copies the deferred messages for saving them. It must be called under lock.
+func (d *Dispatcher) copyDeferred() deferredData {
+	quietHours := make([]Message, len(d.deferred.QuietHours))
+	copy(quietHours, d.deferred.QuietHours)
+	throttled := make(map[string][]Message, len(d.deferred.Throttled))
+	for topic, queue := range d.deferred.Throttled {
+		throttled[topic] = append([]Message(nil), queue...)
+	}
+	return deferredData{QuietHours: quietHours, Throttled: throttled}
+}
+
+func (d *Dispatcher) saveDeferred(deferred deferredData) {
+	if d.storage == nil {
+		return
+	}
+	err := d.storage.SaveState(deferredState, deferred)
+	if err != nil {
+		log.Printf("dispatcher -> saveDeferred: failed to save deferred messages. Reason: %s", err.Error())
+	}
+}
+
+func (d *Dispatcher) markSent(key string, now time.Time) {
+	if len(key) == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	d.sentKeys[key] = now
+	for sentKey, sentAt := range d.sentKeys {
+		if now.Sub(sentAt) > sentKeysMaxAge {
+			delete(d.sentKeys, sentKey)
+		}
+	}
+	sentKeys := make(map[string]time.Time, len(d.sentKeys))
+	for sentKey, sentAt := range d.sentKeys {
+		sentKeys[sentKey] = sentAt
+	}
+	d.mu.Unlock()
+
+	if d.storage != nil {
+		err := d.storage.SaveState(sentKeysState, sentKeys)
+		if err != nil {
+			log.Printf("dispatcher -> markSent: failed to save sent keys. Reason: %s", err.Error())
+		}
+	}
+}
+
+// getLocaleTopicAndKey gives the topic and the idempotency key of the message tagged with its locale
+func getLocaleTopicAndKey(msg Message) (string, string) {
+	topic, key := msg.Topic, msg.Key
+	if len(msg.Locale) > 0 {
+		topic = fmt.Sprintf("%s.%s", msg.Topic, msg.Locale)
+		if len(key) > 0 {
+			key = fmt.Sprintf("%s.%s", msg.Key, msg.Locale)
+		}
+	}
+	return topic, key
+}
+
+// rateLimit gives the min duration between two notifications for the topic. It must be called under lock.
+func (d *Dispatcher) rateLimit(topic string) time.Duration {
+	kind := topic[strings.LastIndex(topic, ".")+1:]
+	seconds, exists := d.config.RateLimits[kind]
+	if !exists {
+		seconds = d.config.RateLimits[defaultRateKey]
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// quietHoursEnd gives the end of the current quiet hours or zero time if now is not in quiet hours. It must be called under lock.
+func (d *Dispatcher) quietHoursEnd(now time.Time) time.Time {
+	quietHours := d.config.QuietHours
+	if !quietHours.Enabled {
+		return time.Time{}
+	}
+	location, err := time.LoadLocation(quietHours.TimeZone)
+	if err != nil {
+		log.Printf("dispatcher -> quietHoursEnd: failed to load time zone %s. Reason: %s", quietHours.TimeZone, err.Error())
+		return time.Time{}
+	}
+	start, errStart := time.Parse(quietTimeLayout, quietHours.Start)
+	end, errEnd := time.Parse(quietTimeLayout, quietHours.End)
+	if errStart != nil || errEnd != nil {
+		log.Printf("dispatcher -> quietHoursEnd: invalid quiet hours %s - %s", quietHours.Start, quietHours.End)
+		return time.Time{}
+	}
+
+	local := now.In(location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	startToday := midnight.Add(time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute)
+	endToday := midnight.Add(time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute)
+
+	if !startToday.After(endToday) {
+		// for example 01:00 - 06:00
+		if !local.Before(startToday) && local.Before(endToday) {
+			return endToday
+		}
+		return time.Time{}
+	}
+	// over midnight, for example 22:00 - 07:00
+	if local.Before(endToday) {
+		return endToday
+	}
+	if !local.Before(startToday) {
+		return endToday.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
@@ -0,0 +1,298 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStorage keeps the saved states in the memory like the storage adapter keeps them in files
+type memoryStorage struct {
+	mu     sync.Mutex
+	states map[string][]byte
+	saves  map[string]int
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{states: make(map[string][]byte), saves: make(map[string]int)}
+}
+
+func (storage *memoryStorage) LoadState(name string, value interface{}) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	data, ok := storage.states[name]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(data, value)
+}
+
+func (storage *memoryStorage) SaveState(name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	storage.mu.Lock()
+	storage.states[name] = data
+	storage.saves[name]++
+	storage.mu.Unlock()
+	return nil
+}
+
+func (storage *memoryStorage) saveCount(name string) int {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	return storage.saves[name]
+}
+
+// quietHoursNow gives the quiet hours from an hour before now until an hour after now
+func quietHoursNow() QuietHoursConfig {
+	now := time.Now().UTC()
+	return QuietHoursConfig{Enabled: true, Start: now.Add(-time.Hour).Format(quietTimeLayout), End: now.Add(time.Hour).Format(quietTimeLayout), TimeZone: "UTC"}
+}
+
+// newTestDispatcher creates a dispatcher with an outbox which is not started, so the sent messages stay pending in it
+func newTestDispatcher(storage Storage, config DispatcherConfig) (*Dispatcher, *Outbox) {
+	outbox := NewOutbox(NewRecorder(), nil, OutboxConfig{MaxAttempts: 3, InitialBackoffSeconds: 1, MaxBackoffSeconds: 10})
+	if storage == nil {
+		return NewDispatcher(outbox, nil, config), outbox
+	}
+	return NewDispatcher(outbox, storage, config), outbox
+}
+
+func TestDispatcherSendNotification(t *testing.T) {
+	rateLimits := map[string]int{defaultRateKey: 60, "news": 0}
+	tests := []struct {
+		name      string
+		config    DispatcherConfig
+		messages  []Message
+		sent      []string // the titles of the messages in the outbox
+		throttled []string // the titles of the throttled messages
+		deferred  []string // the titles of the messages delayed for the quiet hours
+	}{
+		{"the same key is sent once", DispatcherConfig{}, []Message{
+			{Topic: "athletics.football.notification.start", Title: "a", Key: "k1"},
+			{Topic: "athletics.football.notification.start", Title: "b", Key: "k1"},
+		}, []string{"a"}, nil, nil},
+		{"the same key is sent once per locale", DispatcherConfig{}, []Message{
+			{Topic: "athletics.football.notification.start", Title: "a", Key: "k1"},
+			{Topic: "athletics.football.notification.start", Title: "b", Key: "k1", Locale: "es"},
+		}, []string{"a", "b"}, nil, nil},
+		{"messages without key are not deduplicated", DispatcherConfig{}, []Message{
+			{Topic: "athletics.football.notification.start", Title: "a"},
+			{Topic: "athletics.football.notification.start", Title: "b"},
+		}, []string{"a", "b"}, nil, nil},
+		{"the message within the window is throttled", DispatcherConfig{RateLimits: rateLimits}, []Message{
+			{Topic: "athletics.football.notification.start", Title: "a", Key: "k1"},
+			{Topic: "athletics.football.notification.start", Title: "b", Key: "k2"},
+			{Topic: "athletics.football.notification.start", Title: "c", Key: "k3"},
+		}, []string{"a"}, []string{"b", "c"}, nil},
+		{"the throttled key is deferred once", DispatcherConfig{RateLimits: rateLimits}, []Message{
+			{Topic: "athletics.football.notification.start", Title: "a", Key: "k1"},
+			{Topic: "athletics.football.notification.start", Title: "b", Key: "k2"},
+			{Topic: "athletics.football.notification.start", Title: "b", Key: "k2"},
+		}, []string{"a"}, []string{"b"}, nil},
+		{"the latest live alert replaces the throttled one", DispatcherConfig{RateLimits: rateLimits}, []Message{
+			{Topic: "athletics.football.notification.touchdown", Title: "a", Key: "k1", Live: true},
+			{Topic: "athletics.football.notification.touchdown", Title: "b", Key: "k2", Live: true},
+			{Topic: "athletics.football.notification.touchdown", Title: "c", Key: "k3", Live: true},
+		}, []string{"a"}, []string{"c"}, nil},
+		{"the topics are throttled separately", DispatcherConfig{RateLimits: rateLimits}, []Message{
+			{Topic: "athletics.football.notification.start", Title: "a", Key: "k1"},
+			{Topic: "athletics.mbball.notification.start", Title: "b", Key: "k2"},
+			{Topic: "athletics.football.notification.start", Title: "c", Key: "k3", Locale: "es"},
+		}, []string{"a", "b", "c"}, nil, nil},
+		{"zero rate limit does not throttle", DispatcherConfig{RateLimits: rateLimits}, []Message{
+			{Topic: "athletics.news", Title: "a", Key: "k1"},
+			{Topic: "athletics.news", Title: "b", Key: "k2"},
+		}, []string{"a", "b"}, nil, nil},
+		{"unthrottled messages are not throttled", DispatcherConfig{RateLimits: rateLimits}, []Message{
+			{Topic: "athletics.football.notification.start", Title: "a", Key: "k1", Unthrottled: true},
+			{Topic: "athletics.football.notification.start", Title: "b", Key: "k2", Unthrottled: true},
+		}, []string{"a", "b"}, nil, nil},
+		{"quiet hours delay the messages", DispatcherConfig{QuietHours: quietHoursNow()}, []Message{
+			{Topic: "athletics.football.notification.reminder", Title: "a", Key: "k1"},
+			{Topic: "athletics.football.notification.reminder", Title: "b", Key: "k2"},
+		}, nil, nil, []string{"a", "b"}},
+		{"quiet hours delay the key once", DispatcherConfig{QuietHours: quietHoursNow()}, []Message{
+			{Topic: "athletics.football.notification.reminder", Title: "a", Key: "k1"},
+			{Topic: "athletics.football.notification.reminder", Title: "a", Key: "k1"},
+			{Topic: "athletics.football.notification.reminder", Title: "a", Key: "k1"},
+		}, nil, nil, []string{"a"}},
+		{"quiet hours do not delay the live messages", DispatcherConfig{QuietHours: quietHoursNow()}, []Message{
+			{Topic: "athletics.football.notification.touchdown", Title: "a", Key: "k1", Live: true},
+		}, []string{"a"}, nil, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dispatcher, outbox := newTestDispatcher(nil, test.config)
+			for _, msg := range test.messages {
+				if err := dispatcher.SendNotification(msg); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var sent []string
+			for _, msg := range outbox.GetOutbox().Pending {
+				sent = append(sent, msg.Title)
+			}
+			assertTitles(t, "sent", sent, test.sent)
+
+			dispatcher.mu.Lock()
+			var throttled, deferred []string
+			for _, queue := range dispatcher.deferred.Throttled {
+				for _, msg := range queue {
+					throttled = append(throttled, msg.Title)
+				}
+			}
+			for _, msg := range dispatcher.deferred.QuietHours {
+				deferred = append(deferred, msg.Title)
+			}
+			dispatcher.mu.Unlock()
+			assertTitles(t, "throttled", throttled, test.throttled)
+			assertTitles(t, "deferred", deferred, test.deferred)
+		})
+	}
+}
+
+func assertTitles(t *testing.T, kind string, titles []string, expected []string) {
+	t.Helper()
+	if len(titles) != len(expected) {
+		t.Errorf("%s %v, expected %v", kind, titles, expected)
+		return
+	}
+	for i := range titles {
+		if titles[i] != expected[i] {
+			t.Errorf("%s %v, expected %v", kind, titles, expected)
+			return
+		}
+	}
+}
+
+func TestDispatcherDeferredStateDoesNotGrow(t *testing.T) {
+	storage := newMemoryStorage()
+	dispatcher, _ := newTestDispatcher(storage, DispatcherConfig{QuietHours: quietHoursNow()})
+	reminder := Message{Topic: "athletics.football.notification.reminder", Title: "a", Key: "reminder.1001.60"}
+	for i := 0; i < 5; i++ {
+		dispatcher.SendNotification(reminder)
+	}
+
+	var saved deferredData
+	storage.LoadState(deferredState, &saved)
+	if len(saved.QuietHours) != 1 {
+		t.Errorf("%d copies of the reminder are saved, expected 1", len(saved.QuietHours))
+	}
+	if saves := storage.saveCount(deferredState); saves != 1 {
+		t.Errorf("the deferred messages are saved %d times, expected 1", saves)
+	}
+}
+
+func TestDispatcherDropsLoadedCopies(t *testing.T) {
+	storage := newMemoryStorage()
+	reminder := Message{Topic: "athletics.football.notification.reminder", Title: "a", Key: "reminder.1001.60"}
+	other := Message{Topic: "athletics.football.notification.reminder", Title: "b", Key: "reminder.1002.60"}
+	storage.SaveState(deferredState, deferredData{QuietHours: []Message{reminder, reminder, other, reminder}})
+
+	dispatcher, _ := newTestDispatcher(storage, DispatcherConfig{QuietHours: quietHoursNow()})
+	dispatcher.SendNotification(reminder)
+
+	dispatcher.mu.Lock()
+	defer dispatcher.mu.Unlock()
+	if len(dispatcher.deferred.QuietHours) != 2 {
+		t.Errorf("deferred %+v, expected one copy of every message", dispatcher.deferred.QuietHours)
+	}
+}
+
+func TestDispatcherSendData(t *testing.T) {
+	dispatcher, outbox := newTestDispatcher(nil, DispatcherConfig{CoalesceSeconds: 1})
+	pendingScore := func() string {
+		pending := outbox.GetOutbox().Pending
+		if len(pending) != 1 {
+			return ""
+		}
+		return pending[0].Data["HomeScore"]
+	}
+
+	//the first update is sent at once
+	dispatcher.SendData("football", map[string]string{"GameId": "1001", "HomeScore": "7"})
+	if score := pendingScore(); score != "7" {
+		t.Fatalf("the first update is not sent at once, pending score %s", score)
+	}
+
+	//the updates within the window are merged into the latest one
+	dispatcher.SendData("football", map[string]string{"GameId": "1001", "HomeScore": "10"})
+	dispatcher.SendData("football", map[string]string{"GameId": "1001", "HomeScore": "14"})
+	if score := pendingScore(); score != "7" {
+		t.Errorf("the update within the window is sent at once, pending score %s", score)
+	}
+	time.Sleep(1500 * time.Millisecond)
+	if score := pendingScore(); score != "14" {
+		t.Errorf("the latest update is not sent at the end of the window, pending score %s", score)
+	}
+
+	//the window is closed after a window without updates
+	time.Sleep(1500 * time.Millisecond)
+	dispatcher.SendData("football", map[string]string{"GameId": "1001", "HomeScore": "21"})
+	if score := pendingScore(); score != "21" {
+		t.Errorf("the update after the quiet window is not sent at once, pending score %s", score)
+	}
+}
+
+func TestDispatcherSendDataWithoutCoalescing(t *testing.T) {
+	dispatcher, outbox := newTestDispatcher(nil, DispatcherConfig{})
+	dispatcher.SendData("football", map[string]string{"GameId": "1001", "HomeScore": "7"})
+	dispatcher.SendData("mbball", map[string]string{"GameId": "1002", "HomeScore": "2"})
+	if pending := outbox.GetOutbox().Pending; len(pending) != 2 {
+		t.Errorf("pending %+v, expected both updates", pending)
+	}
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("the time zone database is not available")
+	}
+	overnight := QuietHoursConfig{Enabled: true, Start: "22:00", End: "07:00", TimeZone: "America/Chicago"}
+	early := QuietHoursConfig{Enabled: true, Start: "01:00", End: "06:00", TimeZone: "America/Chicago"}
+	tests := []struct {
+		name     string
+		config   QuietHoursConfig
+		now      time.Time
+		expected time.Time
+	}{
+		{"disabled", QuietHoursConfig{Start: "22:00", End: "07:00", TimeZone: "America/Chicago"}, time.Date(2023, 10, 21, 23, 0, 0, 0, chicago), time.Time{}},
+		{"before the overnight quiet hours", overnight, time.Date(2023, 10, 21, 21, 59, 0, 0, chicago), time.Time{}},
+		{"in the evening of the overnight quiet hours", overnight, time.Date(2023, 10, 21, 23, 0, 0, 0, chicago), time.Date(2023, 10, 22, 7, 0, 0, 0, chicago)},
+		{"in the morning of the overnight quiet hours", overnight, time.Date(2023, 10, 22, 6, 30, 0, 0, chicago), time.Date(2023, 10, 22, 7, 0, 0, 0, chicago)},
+		{"the end of the overnight quiet hours", overnight, time.Date(2023, 10, 22, 7, 0, 0, 0, chicago), time.Time{}},
+		{"in the quiet hours within a day", early, time.Date(2023, 10, 22, 3, 0, 0, 0, chicago), time.Date(2023, 10, 22, 6, 0, 0, 0, chicago)},
+		{"after the quiet hours within a day", early, time.Date(2023, 10, 22, 23, 0, 0, 0, chicago), time.Time{}},
+		{"in the time zone of the config", overnight, time.Date(2023, 10, 22, 4, 0, 0, 0, time.UTC), time.Date(2023, 10, 22, 7, 0, 0, 0, chicago)},
+		{"invalid time zone", QuietHoursConfig{Enabled: true, Start: "22:00", End: "07:00", TimeZone: "Nowhere"}, time.Date(2023, 10, 21, 23, 0, 0, 0, chicago), time.Time{}},
+		{"invalid hours", QuietHoursConfig{Enabled: true, Start: "10pm", End: "07:00", TimeZone: "America/Chicago"}, time.Date(2023, 10, 21, 23, 0, 0, 0, chicago), time.Time{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dispatcher := &Dispatcher{config: DispatcherConfig{QuietHours: test.config}}
+			if end := dispatcher.quietHoursEnd(test.now); !end.Equal(test.expected) {
+				t.Errorf("end %s, expected %s", end, test.expected)
+			}
+		})
+	}
+}
@@ -22,9 +22,13 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"time"
 )
 
-// Notifications structure
+// a hung request must not block the delivery of the other messages
+const requestTimeout = 10 * time.Second
+
+// Notifications sends the messages to the Rokwire Notifications BB
 type Notifications struct {
 	apiKey string
 	host   string
@@ -33,8 +37,8 @@ type Notifications struct {
 }
 
 // New creates new instance
-func New(apiKey string, host string, appID string, orgID string) Notifications {
-	return Notifications{apiKey: apiKey, host: host, appID: appID, orgID: orgID}
+func New(apiKey string, host string, appID string, orgID string) *Notifications {
+	return &Notifications{apiKey: apiKey, host: host, appID: appID, orgID: orgID}
 }
 
 // SendDataMsg sends data message
@@ -115,7 +119,7 @@ func (n *Notifications) sendNotification(body io.Reader) (*int, *string, error)
 	}
 
 	req.Header.Set("INTERNAL-API-KEY", n.apiKey)
-	client := &http.Client{Transport: &http.Transport{}}
+	client := &http.Client{Transport: &http.Transport{}, Timeout: requestTimeout}
 	resp, err := client.Do(req)
 
 	if err != nil {
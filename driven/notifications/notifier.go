@@ -0,0 +1,199 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// TransportRokwire sends the messages to the Rokwire Notifications BB
+	TransportRokwire string = "rokwire"
+	// TransportWebhook posts the messages to a webhook
+	TransportWebhook string = "webhook"
+	// TransportRecording keeps the messages in memory
+	TransportRecording string = "recording"
+	// TransportDryRun only logs the messages
+	TransportDryRun string = "dry_run"
+
+	webhookTimeout = 10 * time.Second
+)
+
+// Notifier delivers the messages to the users
+type Notifier interface {
+	SendNotificationMsg(topic string, title string, body string, data map[string]string) error
+	SendDataMsg(topic string, data map[string]string) error
+}
+
+// NotifierConfig structure
+type NotifierConfig struct {
+	Transport      string            `json:"transport"` // rokwire, webhook, recording or dry_run
+	WebhookURL     string            `json:"webhook_url"`
+	WebhookHeaders map[string]string `json:"webhook_headers"`
+}
+
+// NewNotifier creates the notifier for the configured transport. The Rokwire notifier is used for unknown transports.
+func NewNotifier(config NotifierConfig, rokwire Notifier) Notifier {
+	switch config.Transport {
+	case TransportWebhook:
+		return NewWebhook(config.WebhookURL, config.WebhookHeaders)
+	case TransportRecording:
+		return NewRecorder()
+	case TransportDryRun:
+		return DryRun{}
+	case TransportRokwire, "":
+		return rokwire
+	default:
+		log.Printf("notifier -> NewNotifier: unknown transport %s, use %s", config.Transport, TransportRokwire)
+		return rokwire
+	}
+}
+
+// RecordedMessage structure
+type RecordedMessage struct {
+	Type  string // notification or data
+	Topic string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Recorder keeps the messages in memory instead of sending them
+type Recorder struct {
+	mu       sync.Mutex
+	messages []RecordedMessage
+}
+
+// NewRecorder creates new recorder instance
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// SendNotificationMsg records notification message
+func (r *Recorder) SendNotificationMsg(topic string, title string, body string, data map[string]string) error {
+	r.record(RecordedMessage{Type: msgTypeNotify, Topic: topic, Title: title, Body: body, Data: data})
+	return nil
+}
+
+// SendDataMsg records data message
+func (r *Recorder) SendDataMsg(topic string, data map[string]string) error {
+	r.record(RecordedMessage{Type: msgTypeData, Topic: topic, Data: data})
+	return nil
+}
+
+// Messages gives the recorded messages
+func (r *Recorder) Messages() []RecordedMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	messages := make([]RecordedMessage, len(r.messages))
+	copy(messages, r.messages)
+	return messages
+}
+
+// Reset removes the recorded messages
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.messages = nil
+	r.mu.Unlock()
+}
+
+func (r *Recorder) record(msg RecordedMessage) {
+	r.mu.Lock()
+	r.messages = append(r.messages, msg)
+	r.mu.Unlock()
+}
+
+// DryRun only logs the messages
+type DryRun struct{}
+
+// SendNotificationMsg logs notification message
+func (DryRun) SendNotificationMsg(topic string, title string, body string, data map[string]string) error {
+	log.Printf("notifier -> dry run: notification topic:%s title:%s body:%s data:%v", topic, title, body, data)
+	return nil
+}
+
+// SendDataMsg logs data message
+func (DryRun) SendDataMsg(topic string, data map[string]string) error {
+	log.Printf("notifier -> dry run: data topic:%s data:%v", topic, data)
+	return nil
+}
+
+// Webhook posts the messages as json to an url
+type Webhook struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhook creates new webhook instance
+func NewWebhook(url string, headers map[string]string) *Webhook {
+	return &Webhook{url: url, headers: headers, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// SendNotificationMsg posts notification message
+func (w *Webhook) SendNotificationMsg(topic string, title string, body string, data map[string]string) error {
+	return w.post(map[string]interface{}{"type": msgTypeNotify, "topic": topic, "subject": title, "body": body, "data": data})
+}
+
+// SendDataMsg posts data message
+func (w *Webhook) SendDataMsg(topic string, data map[string]string) error {
+	return w.post(map[string]interface{}{"type": msgTypeData, "topic": topic, "data": data})
+}
+
+func (w *Webhook) post(bodyJSON map[string]interface{}) error {
+	if w.url == "" {
+		return fmt.Errorf("missing webhook url")
+	}
+
+	bodyByteArr, err := json.Marshal(bodyJSON)
+	if err != nil {
+		log.Printf("webhook -> post: failed to Marshal body. Reason: %s", err.Error())
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(bodyByteArr))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		log.Printf("webhook -> post: failed to send message. Reason: %s", err.Error())
+		return err
+	}
+	responseBytes, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		log.Printf("webhook -> post: request failed with code %d. Reason: %s", resp.StatusCode, string(responseBytes))
+		return fmt.Errorf("%d: %s", resp.StatusCode, string(responseBytes))
+	}
+	return nil
+}
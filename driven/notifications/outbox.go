@@ -0,0 +1,351 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"sport/core/model"
+	"sync"
+	"time"
+)
+
+const (
+	outboxState      string = "notification_outbox"
+	msgTypeNotify    string = "notification"
+	msgTypeData      string = "data"
+	maxDeadLetters          = 500
+	outboxIdleWakeup        = time.Minute
+	// the data messages and the delivery status changes within this delay are saved together because the live data
+	// messages come every few seconds
+	outboxSaveDelay = time.Second
+)
+
+// OutboxConfig structure
+type OutboxConfig struct {
+	MaxAttempts           int `json:"max_attempts"`
+	InitialBackoffSeconds int `json:"initial_backoff_seconds"`
+	MaxBackoffSeconds     int `json:"max_backoff_seconds"`
+}
+
+// Validate checks that the messages are attempted at least once and the retries are not in a hot loop
+func (c OutboxConfig) Validate() error {
+	if c.MaxAttempts < 1 {
+		return fmt.Errorf("outbox max attempts must be at least 1 - current is [%d]", c.MaxAttempts)
+	}
+	if c.InitialBackoffSeconds < 1 {
+		return fmt.Errorf("outbox initial backoff must be at least 1 second - current is [%d]", c.InitialBackoffSeconds)
+	}
+	if c.MaxBackoffSeconds < c.InitialBackoffSeconds {
+		return fmt.Errorf("outbox max backoff must not be less than the initial backoff - current is [%d]", c.MaxBackoffSeconds)
+	}
+	return nil
+}
+
+type outboxData struct {
+	Pending     []model.OutboxMessage `json:"pending"`
+	DeadLetters []model.OutboxMessage `json:"dead_letters"`
+}
+
+// Outbox persists the outgoing messages and delivers them asynchronously with retries
+type Outbox struct {
+	mu       sync.Mutex
+	notifier Notifier
+	storage  Storage
+	config   OutboxConfig
+	data     outboxData
+	sequence int64
+	wake     chan struct{}
+	// the timer which saves the changes, nil if there are no unsaved changes
+	saveTimer *time.Timer
+}
+
+// NewOutbox creates new outbox instance and loads the messages which were not delivered before the restart
+func NewOutbox(notifier Notifier, storage Storage, config OutboxConfig) *Outbox {
+	var data outboxData
+	if storage != nil {
+		err := storage.LoadState(outboxState, &data)
+		if err != nil {
+			log.Printf("outbox -> NewOutbox: failed to load outbox. Reason: %s", err.Error())
+		}
+	}
+	if len(data.Pending) > 0 {
+		log.Printf("outbox -> NewOutbox: %d pending messages loaded", len(data.Pending))
+	}
+	return &Outbox{notifier: notifier, storage: storage, config: config, data: data, wake: make(chan struct{}, 1)}
+}
+
+// Start starts delivering the messages
+func (o *Outbox) Start() {
+	go o.process()
+}
+
+// UpdateConfig updates the outbox config
+func (o *Outbox) UpdateConfig(config OutboxConfig) {
+	o.mu.Lock()
+	o.config = config
+	o.mu.Unlock()
+	log.Println("outbox -> UpdateConfig: config updated")
+}
+
+// SetNotifier changes the transport which delivers the messages
+func (o *Outbox) SetNotifier(notifier Notifier) {
+	o.mu.Lock()
+	o.notifier = notifier
+	o.mu.Unlock()
+	log.Println("outbox -> SetNotifier: notifier updated")
+}
+
+// EnqueueNotification adds notification message for delivery
+func (o *Outbox) EnqueueNotification(topic string, title string, body string, data map[string]string) {
+	o.enqueue(model.OutboxMessage{Type: msgTypeNotify, Topic: topic, Title: title, Body: body, Data: data})
+}
+
+// EnqueueData adds data message for delivery. It replaces the pending data message for the same topic and game
+// because delivering an older score after a newer one is worse than not delivering it.
+func (o *Outbox) EnqueueData(topic string, data map[string]string) {
+	o.enqueue(model.OutboxMessage{Type: msgTypeData, Topic: topic, Data: data})
+}
+
+// GetOutbox gives the pending and the dead-lettered messages
+func (o *Outbox) GetOutbox() model.NotificationsOutbox {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	pending := make([]model.OutboxMessage, len(o.data.Pending))
+	copy(pending, o.data.Pending)
+	deadLetters := make([]model.OutboxMessage, len(o.data.DeadLetters))
+	copy(deadLetters, o.data.DeadLetters)
+	return model.NotificationsOutbox{Pending: pending, DeadLetters: deadLetters}
+}
+
+// Replay moves the dead-lettered message with the given id or all of them if id is nil back for delivery
+func (o *Outbox) Replay(id *string) int {
+	o.mu.Lock()
+	now := time.Now()
+	var remaining []model.OutboxMessage
+	replayed := 0
+	for _, msg := range o.data.DeadLetters {
+		if id != nil && msg.ID != *id {
+			remaining = append(remaining, msg)
+			continue
+		}
+		msg.Attempts = 0
+		msg.NextAttempt = now
+		o.data.Pending = append(o.data.Pending, msg)
+		replayed++
+	}
+	o.data.DeadLetters = remaining
+	o.saveLocked()
+	o.mu.Unlock()
+
+	log.Printf("outbox -> Replay: %d messages replayed", replayed)
+	o.notify()
+	return replayed
+}
+
+func (o *Outbox) enqueue(msg model.OutboxMessage) {
+	o.mu.Lock()
+	now := time.Now()
+	o.sequence++
+	msg.ID = fmt.Sprintf("%d-%d", now.UnixNano(), o.sequence)
+	msg.CreatedAt = now
+	msg.NextAttempt = now
+
+	if msg.Type == msgTypeData {
+		for i, pending := range o.data.Pending {
+			if pending.Type == msgTypeData && pending.Topic == msg.Topic && pending.Data["GameId"] == msg.Data["GameId"] {
+				o.data.Pending = append(o.data.Pending[:i], o.data.Pending[i+1:]...)
+				break
+			}
+		}
+	}
+	o.data.Pending = append(o.data.Pending, msg)
+	if msg.Type == msgTypeNotify {
+		//a notification is sent once, so it must not be lost if the service stops within the save delay
+		o.saveNowLocked()
+	} else {
+		o.saveLocked()
+	}
+	o.mu.Unlock()
+
+	o.notify()
+}
+
+func (o *Outbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (o *Outbox) process() {
+	for {
+		msg, wait := o.next()
+		if msg == nil {
+			timer := time.NewTimer(wait)
+			select {
+			case <-o.wake:
+				timer.Stop()
+			case <-timer.C:
+			}
+			continue
+		}
+
+		err := o.deliver(*msg)
+		o.complete(*msg, err)
+	}
+}
+
+// next gives the first message which is due for delivery or how long to wait for one
+func (o *Outbox) next() (*model.OutboxMessage, time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	wait := outboxIdleWakeup
+	for _, msg := range o.data.Pending {
+		if !msg.NextAttempt.After(now) {
+			due := msg
+			return &due, 0
+		}
+		if untilNext := msg.NextAttempt.Sub(now); untilNext < wait {
+			wait = untilNext
+		}
+	}
+	return nil, wait
+}
+
+func (o *Outbox) deliver(msg model.OutboxMessage) error {
+	o.mu.Lock()
+	notifier := o.notifier
+	o.mu.Unlock()
+
+	if msg.Type == msgTypeData {
+		return notifier.SendDataMsg(msg.Topic, msg.Data)
+	}
+	return notifier.SendNotificationMsg(msg.Topic, msg.Title, msg.Body, msg.Data)
+}
+
+// complete removes the delivered message or schedules the next attempt with exponential backoff
+func (o *Outbox) complete(msg model.OutboxMessage, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	index := -1
+	for i, pending := range o.data.Pending {
+		if pending.ID == msg.ID {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		// it was replaced by a newer data message during the delivery
+		return
+	}
+
+	if err == nil {
+		o.data.Pending = append(o.data.Pending[:index], o.data.Pending[index+1:]...)
+		o.saveLocked()
+		return
+	}
+
+	pending := &o.data.Pending[index]
+	pending.Attempts++
+	pending.LastError = err.Error()
+	if pending.Attempts >= o.maxAttempts() {
+		log.Printf("outbox -> complete: move message %s for topic %s to dead letters after %d attempts. Reason: %s", pending.ID, pending.Topic, pending.Attempts, pending.LastError)
+		o.data.DeadLetters = append(o.data.DeadLetters, *pending)
+		if len(o.data.DeadLetters) > maxDeadLetters {
+			o.data.DeadLetters = o.data.DeadLetters[len(o.data.DeadLetters)-maxDeadLetters:]
+		}
+		o.data.Pending = append(o.data.Pending[:index], o.data.Pending[index+1:]...)
+	} else {
+		backoff := o.backoff(pending.Attempts)
+		pending.NextAttempt = time.Now().Add(backoff)
+		log.Printf("outbox -> complete: retry message %s for topic %s after %s. Reason: %s", pending.ID, pending.Topic, backoff, pending.LastError)
+	}
+	o.saveLocked()
+}
+
+// maxAttempts gives the configured max attempts but at least one. It must be called under lock.
+func (o *Outbox) maxAttempts() int {
+	if o.config.MaxAttempts < 1 {
+		return 1
+	}
+	return o.config.MaxAttempts
+}
+
+// backoff gives the delay before the next attempt, it is at least one second. It must be called under lock.
+func (o *Outbox) backoff(attempts int) time.Duration {
+	backoff := time.Duration(o.config.InitialBackoffSeconds) * time.Second
+	if backoff < time.Second {
+		backoff = time.Second
+	}
+	maxBackoff := time.Duration(o.config.MaxBackoffSeconds) * time.Second
+	if maxBackoff < backoff {
+		maxBackoff = backoff
+	}
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// saveLocked schedules persisting the outbox, the changes within the save delay are written once. It must be called under lock.
+func (o *Outbox) saveLocked() {
+	if o.storage == nil || o.saveTimer != nil {
+		return
+	}
+	o.saveTimer = time.AfterFunc(outboxSaveDelay, o.save)
+}
+
+// saveNowLocked persists the outbox at once together with the scheduled changes. It must be called under lock.
+func (o *Outbox) saveNowLocked() {
+	if o.storage == nil {
+		return
+	}
+	if o.saveTimer != nil {
+		o.saveTimer.Stop()
+		o.saveTimer = nil
+	}
+	err := o.storage.SaveState(outboxState, o.copyDataLocked())
+	if err != nil {
+		log.Printf("outbox -> saveNowLocked: failed to save outbox. Reason: %s", err.Error())
+	}
+}
+
+func (o *Outbox) save() {
+	o.mu.Lock()
+	o.saveTimer = nil
+	data := o.copyDataLocked()
+	o.mu.Unlock()
+
+	err := o.storage.SaveState(outboxState, data)
+	if err != nil {
+		log.Printf("outbox -> save: failed to save outbox. Reason: %s", err.Error())
+	}
+}
+
+// copyDataLocked gives a copy of the messages to save. It must be called under lock.
+func (o *Outbox) copyDataLocked() outboxData {
+	data := outboxData{Pending: make([]model.OutboxMessage, len(o.data.Pending)), DeadLetters: make([]model.OutboxMessage, len(o.data.DeadLetters))}
+	copy(data.Pending, o.data.Pending)
+	copy(data.DeadLetters, o.data.DeadLetters)
+	return data
+}
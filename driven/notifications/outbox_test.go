@@ -0,0 +1,214 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"errors"
+	"sport/core/model"
+	"testing"
+	"time"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   OutboxConfig
+		attempts int
+		expected time.Duration
+	}{
+		{"first retry", OutboxConfig{InitialBackoffSeconds: 2, MaxBackoffSeconds: 60}, 1, 2 * time.Second},
+		{"second retry doubles", OutboxConfig{InitialBackoffSeconds: 2, MaxBackoffSeconds: 60}, 2, 4 * time.Second},
+		{"fourth retry", OutboxConfig{InitialBackoffSeconds: 2, MaxBackoffSeconds: 60}, 4, 16 * time.Second},
+		{"capped by the max backoff", OutboxConfig{InitialBackoffSeconds: 2, MaxBackoffSeconds: 60}, 10, 60 * time.Second},
+		{"at least one second", OutboxConfig{}, 1, time.Second},
+		{"max backoff below the initial one", OutboxConfig{InitialBackoffSeconds: 5, MaxBackoffSeconds: 1}, 3, 5 * time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outbox := NewOutbox(NewRecorder(), nil, test.config)
+			if backoff := outbox.backoff(test.attempts); backoff != test.expected {
+				t.Errorf("backoff %s, expected %s", backoff, test.expected)
+			}
+		})
+	}
+}
+
+func TestOutboxConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config OutboxConfig
+		valid  bool
+	}{
+		{"valid", OutboxConfig{MaxAttempts: 5, InitialBackoffSeconds: 2, MaxBackoffSeconds: 60}, true},
+		{"no attempts", OutboxConfig{MaxAttempts: 0, InitialBackoffSeconds: 2, MaxBackoffSeconds: 60}, false},
+		{"no backoff", OutboxConfig{MaxAttempts: 5, InitialBackoffSeconds: 0, MaxBackoffSeconds: 60}, false},
+		{"max backoff below the initial one", OutboxConfig{MaxAttempts: 5, InitialBackoffSeconds: 10, MaxBackoffSeconds: 5}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.config.Validate(); (err == nil) != test.valid {
+				t.Errorf("error %v, expected valid %t", err, test.valid)
+			}
+		})
+	}
+}
+
+func TestOutboxRetriesAndDeadLetters(t *testing.T) {
+	failure := errors.New("unavailable")
+	tests := []struct {
+		name        string
+		maxAttempts int
+		results     []error
+		pending     int
+		deadLetters int
+		attempts    int
+	}{
+		{"delivered", 3, []error{nil}, 0, 0, 0},
+		{"delivered after a retry", 3, []error{failure, nil}, 0, 0, 0},
+		{"retried after a failure", 3, []error{failure}, 1, 0, 1},
+		{"dead letter after the max attempts", 3, []error{failure, failure, failure}, 0, 1, 3},
+		{"dead letter after one attempt", 1, []error{failure}, 0, 1, 1},
+		{"at least one attempt", 0, []error{failure}, 0, 1, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outbox := NewOutbox(NewRecorder(), nil, OutboxConfig{MaxAttempts: test.maxAttempts, InitialBackoffSeconds: 1, MaxBackoffSeconds: 10})
+			outbox.EnqueueNotification("athletics.football.notification.start", "title", "body", nil)
+			for _, result := range test.results {
+				msg := outbox.GetOutbox().Pending[0]
+				outbox.complete(msg, result)
+			}
+
+			state := outbox.GetOutbox()
+			if len(state.Pending) != test.pending || len(state.DeadLetters) != test.deadLetters {
+				t.Fatalf("%d pending and %d dead letters, expected %d and %d", len(state.Pending), len(state.DeadLetters), test.pending, test.deadLetters)
+			}
+			var failed []model.OutboxMessage
+			failed = append(failed, state.Pending...)
+			failed = append(failed, state.DeadLetters...)
+			for _, msg := range failed {
+				if msg.Attempts != test.attempts || msg.LastError != failure.Error() {
+					t.Errorf("attempts %d error %s, expected %d attempts", msg.Attempts, msg.LastError, test.attempts)
+				}
+			}
+			for _, msg := range state.Pending {
+				if !msg.NextAttempt.After(time.Now()) {
+					t.Error("the failed message is due at once")
+				}
+			}
+		})
+	}
+}
+
+func TestOutboxReplay(t *testing.T) {
+	failure := errors.New("unavailable")
+	outbox := NewOutbox(NewRecorder(), nil, OutboxConfig{MaxAttempts: 1, InitialBackoffSeconds: 1, MaxBackoffSeconds: 10})
+	for _, title := range []string{"a", "b", "c"} {
+		outbox.EnqueueNotification("athletics.football.notification.start", title, "body", nil)
+		outbox.complete(outbox.GetOutbox().Pending[0], failure)
+	}
+	deadLetters := outbox.GetOutbox().DeadLetters
+
+	unknown := "unknown"
+	if replayed := outbox.Replay(&unknown); replayed != 0 {
+		t.Errorf("%d messages replayed for an unknown id", replayed)
+	}
+	if replayed := outbox.Replay(&deadLetters[1].ID); replayed != 1 {
+		t.Errorf("%d messages replayed for an id, expected 1", replayed)
+	}
+	state := outbox.GetOutbox()
+	if len(state.Pending) != 1 || state.Pending[0].Title != "b" || len(state.DeadLetters) != 2 {
+		t.Fatalf("pending %+v dead letters %+v after replaying b", state.Pending, state.DeadLetters)
+	}
+	if msg := state.Pending[0]; msg.Attempts != 0 || msg.NextAttempt.After(time.Now()) {
+		t.Errorf("the replayed message is not due with a new attempts count %+v", msg)
+	}
+
+	if replayed := outbox.Replay(nil); replayed != 2 {
+		t.Errorf("%d messages replayed, expected 2", replayed)
+	}
+	if state := outbox.GetOutbox(); len(state.Pending) != 3 || len(state.DeadLetters) != 0 {
+		t.Errorf("%d pending and %d dead letters after replaying all", len(state.Pending), len(state.DeadLetters))
+	}
+}
+
+func TestOutboxReplacesPendingData(t *testing.T) {
+	outbox := NewOutbox(NewRecorder(), nil, OutboxConfig{MaxAttempts: 3, InitialBackoffSeconds: 1, MaxBackoffSeconds: 10})
+	outbox.EnqueueData("football", map[string]string{"GameId": "1001", "HomeScore": "7"})
+	outbox.EnqueueData("football", map[string]string{"GameId": "1002", "HomeScore": "3"})
+	outbox.EnqueueNotification("athletics.football.notification.start", "title", "body", map[string]string{"GameId": "1001"})
+	outbox.EnqueueData("football", map[string]string{"GameId": "1001", "HomeScore": "14"})
+
+	pending := outbox.GetOutbox().Pending
+	if len(pending) != 3 {
+		t.Fatalf("pending %+v, expected 3 messages", pending)
+	}
+	last := pending[2]
+	if last.Type != msgTypeData || last.Data["GameId"] != "1001" || last.Data["HomeScore"] != "14" {
+		t.Errorf("the latest data message %+v does not replace the older one", last)
+	}
+	if pending[1].Type != msgTypeNotify {
+		t.Error("the data message has replaced the notification")
+	}
+}
+
+func TestOutboxDelivery(t *testing.T) {
+	recorder := NewRecorder()
+	outbox := NewOutbox(recorder, nil, OutboxConfig{MaxAttempts: 3, InitialBackoffSeconds: 1, MaxBackoffSeconds: 10})
+	outbox.Start()
+	outbox.EnqueueNotification("athletics.football.notification.start", "title", "body", map[string]string{"GameId": "1001"})
+	outbox.EnqueueData("football", map[string]string{"GameId": "1001"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(outbox.GetOutbox().Pending) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	messages := recorder.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("recorded %+v, expected 2 messages", messages)
+	}
+	if messages[0].Type != msgTypeNotify || messages[0].Title != "title" || messages[1].Type != msgTypeData {
+		t.Errorf("recorded %+v, expected the notification and the data message in order", messages)
+	}
+}
+
+func TestOutboxSave(t *testing.T) {
+	storage := newMemoryStorage()
+	outbox := NewOutbox(NewRecorder(), storage, OutboxConfig{MaxAttempts: 3, InitialBackoffSeconds: 1, MaxBackoffSeconds: 10})
+
+	//the notifications are saved at once
+	outbox.EnqueueNotification("athletics.football.notification.start", "title", "body", nil)
+	if saves := storage.saveCount(outboxState); saves != 1 {
+		t.Fatalf("the notification is saved %d times, expected at once", saves)
+	}
+
+	//the data messages are saved together after the delay
+	outbox.EnqueueData("football", map[string]string{"GameId": "1001", "HomeScore": "7"})
+	outbox.EnqueueData("football", map[string]string{"GameId": "1001", "HomeScore": "14"})
+	if saves := storage.saveCount(outboxState); saves != 1 {
+		t.Errorf("the data messages are saved at once")
+	}
+	time.Sleep(outboxSaveDelay + 500*time.Millisecond)
+	if saves := storage.saveCount(outboxState); saves != 2 {
+		t.Errorf("the data messages are saved %d times, expected once more", saves-1)
+	}
+
+	//the saved messages are loaded after a restart
+	loaded := NewOutbox(NewRecorder(), storage, OutboxConfig{MaxAttempts: 3, InitialBackoffSeconds: 1, MaxBackoffSeconds: 10})
+	pending := loaded.GetOutbox().Pending
+	if len(pending) != 2 || pending[0].Type != msgTypeNotify || pending[1].Data["HomeScore"] != "14" {
+		t.Errorf("loaded %+v, expected the notification and the latest data message", pending)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"log"
+	"sport/core/model"
+	"strings"
+)
+
+// GetBoxScore retrieves the box score of the game from the live stats, it gives nil if there is no box score for the game.
+// The players of our team are linked to the current roster by their jersey numbers.
+func (p *Provider) GetBoxScore(gameID int) (*model.GameBoxScore, error) {
+	boxScore := p.stats.BoxScore(gameID)
+	if boxScore == nil {
+		return nil, nil
+	}
+
+	players, err := p.GetPlayers(boxScore.Sport, nil, model.PlayersFilter{})
+	if err != nil {
+		//give the box score without the roster links
+		log.Printf("sidearm -> GetBoxScore: Failed to load the roster for %s. Reason: %s", boxScore.Sport, err.Error())
+		return boxScore, nil
+	}
+
+	//the cached box score is shared, so link the players in a copy
+	result := *boxScore
+	result.Teams = make([]model.BoxScoreTeam, len(boxScore.Teams))
+	for i, team := range boxScore.Teams {
+		if team.OurTeam {
+			team.Players = linkRosterPlayers(team.Players, players)
+		}
+		result.Teams[i] = team
+	}
+	return &result, nil
+}
+
+// linkRosterPlayers sets the roster ids of the box score players with the same jersey numbers
+func linkRosterPlayers(boxPlayers []model.BoxScorePlayer, players []model.Player) []model.BoxScorePlayer {
+	rosterIDs := make(map[string]string)
+	for _, player := range players {
+		if uni := strings.TrimSpace(player.Uni2); len(uni) > 0 {
+			rosterIDs[uni] = player.ID
+		}
+	}
+	//the main jersey numbers win over the second ones
+	for _, player := range players {
+		if uni := strings.TrimSpace(player.Uni); len(uni) > 0 {
+			rosterIDs[uni] = player.ID
+		}
+	}
+
+	linked := make([]model.BoxScorePlayer, len(boxPlayers))
+	for i, player := range boxPlayers {
+		player.RosterID = rosterIDs[strings.TrimSpace(player.Uni)]
+		linked[i] = player
+	}
+	return linked
+}
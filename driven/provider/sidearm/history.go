@@ -0,0 +1,186 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLastMeetings = 5
+	// how many season schedules are loaded at the same time
+	maxScheduleLoads = 4
+	// the schedules of the seasons in progress are cached only for a while
+	scheduleTTL = 10 * time.Minute
+)
+
+type cachedSchedule struct {
+	schedule  *sidearmModel.Schedule
+	completed bool
+	loadedAt  time.Time
+}
+
+// GetTeamHistory retrieves all seasons of the sport with their records, the most recent first
+func (p *Provider) GetTeamHistory(sport string) ([]model.TeamSeason, error) {
+	ss, err := getSportSeasons(sport, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, errs := p.getSeasonSchedules(ss.Seasons)
+	var seasons []model.TeamSeason
+	for i := len(ss.Seasons) - 1; i >= 0; i-- {
+		season := ss.Seasons[i]
+		teamSeason := model.TeamSeason{Year: season.Year, Label: season.ScheduleYear, Current: season.Current}
+		sch, err := schedules[i], errs[i]
+		if err != nil {
+			//the record of a season may be missing, the other seasons are still listed
+			log.Printf("sidearm -> GetTeamHistory: failed to load the schedule of %s season %s. Reason: %s", sport, season.Year, err.Error())
+		} else {
+			record := buildRecord(*sch)
+			teamSeason.Record = &record
+		}
+		seasons = append(seasons, teamSeason)
+	}
+	return seasons, nil
+}
+
+// GetHeadToHead retrieves the summary of the final games against the opponent with the global id across all seasons of the sport
+func (p *Provider) GetHeadToHead(sport string, opponentID int, limit int) (*model.HeadToHead, error) {
+	ss, err := getSportSeasons(sport, nil)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultLastMeetings
+	}
+
+	schedules, errs := p.getSeasonSchedules(ss.Seasons)
+	var meetings []sidearmModel.Game
+	for i, season := range ss.Seasons {
+		sch, err := schedules[i], errs[i]
+		if err != nil {
+			log.Printf("sidearm -> GetHeadToHead: failed to load the schedule of %s season %s. Reason: %s", sport, season.Year, err.Error())
+			continue
+		}
+		for _, game := range sch.Games {
+			if game.Opponent != nil && game.Opponent.GlobalID == opponentID && getFinalResult(game) != nil {
+				meetings = append(meetings, game)
+			}
+		}
+	}
+	sort.SliceStable(meetings, func(i, j int) bool {
+		return meetings[i].DateTimeUtc > meetings[j].DateTimeUtc
+	})
+
+	headToHead := model.HeadToHead{Sport: sport, LastMeetings: []model.Game{}}
+	for i, game := range meetings {
+		headToHead.Games++
+		switch getFinalResult(game).Status {
+		case "W":
+			headToHead.Wins++
+		case "L":
+			headToHead.Losses++
+		default:
+			headToHead.Ties++
+		}
+		if i == 0 {
+			opponent := model.Opponent{GlobalID: game.Opponent.GlobalID, Name: game.Opponent.Name, LogoImage: game.Opponent.LogoImage}
+			headToHead.Opponent = &opponent
+		}
+	}
+	headToHead.Streak = getStreak(meetings)
+
+	if len(meetings) > limit {
+		meetings = meetings[:limit]
+	}
+	headToHead.LastMeetings = append(headToHead.LastMeetings, buildGames(sidearmModel.Schedule{Games: meetings})...)
+	return &headToHead, nil
+}
+
+// getSeasonSchedules loads the schedules of the seasons with a limited number of parallel requests.
+// The schedule and the error of every season are at the index of the season.
+func (p *Provider) getSeasonSchedules(seasons []sidearmModel.Season) ([]*sidearmModel.Schedule, []error) {
+	schedules := make([]*sidearmModel.Schedule, len(seasons))
+	errs := make([]error, len(seasons))
+
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, maxScheduleLoads)
+	for i, season := range seasons {
+		wg.Add(1)
+		go func(i int, season sidearmModel.Season) {
+			defer wg.Done()
+			limit <- struct{}{}
+			schedules[i], errs[i] = p.getSeasonSchedule(season)
+			<-limit
+		}(i, season)
+	}
+	wg.Wait()
+	return schedules, errs
+}
+
+// getSeasonSchedule loads the schedule of the season. The schedules of the completed seasons are cached as they do not change,
+// the other ones are cached for a while.
+func (p *Provider) getSeasonSchedule(season sidearmModel.Season) (*sidearmModel.Schedule, error) {
+	p.mu.Lock()
+	cached, exists := p.schedules[season.ScheduleURL]
+	p.mu.Unlock()
+	if exists && (cached.completed || time.Since(cached.loadedAt) < scheduleTTL) {
+		return cached.schedule, nil
+	}
+
+	sch, err := getSchedule(season)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.schedules[season.ScheduleURL] = cachedSchedule{schedule: sch, completed: !season.Current && isScheduleCompleted(*sch), loadedAt: time.Now()}
+	p.mu.Unlock()
+	return sch, nil
+}
+
+// isScheduleCompleted checks if all games of the schedule were played, the last one at least a day ago
+func isScheduleCompleted(sch sidearmModel.Schedule) bool {
+	dayAgo := time.Now().Add(-24 * time.Hour)
+	for _, game := range sch.Games {
+		start := parseUtcTime(game.DateTimeUtc)
+		if start == nil || start.After(dayAgo) {
+			return false
+		}
+	}
+	return true
+}
+
+// getStreak gives the current streak of the games, the most recent first, for example W3
+func getStreak(games []sidearmModel.Game) string {
+	if len(games) == 0 {
+		return ""
+	}
+	status := getFinalResult(games[0]).Status
+	count := 0
+	for _, game := range games {
+		if getFinalResult(game).Status != status {
+			break
+		}
+		count++
+	}
+	return fmt.Sprintf("%s%d", status, count)
+}
@@ -0,0 +1,155 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package livestats
+
+import (
+	"fmt"
+	"sport/core/model"
+	"sport/driven/provider/sidearm/livestats/source"
+	sidearmModel "sport/driven/provider/sidearm/model"
+)
+
+// scoringAlert is a notification for a scoring play or an important moment of the game
+type scoringAlert struct {
+	kind string // touchdown, field_goal, lead_change, end_of_period, overtime or close_game
+	team string // H or V for the alerts of a team, empty for the alerts of the game
+	body bodyBuilder
+	key  string
+}
+
+// detectScoringAlerts compares the new snapshot of the game with the previous one and gives the alerts to send with
+// the tracker of the game which remembers the alerts sent once for the game
+func (stats *livestats) detectScoringAlerts(transition stateTransition, game model.LiveGame, item *sidearmModel.LiveGameItem) ([]scoringAlert, gameTracker) {
+	current := transition.current
+	alertsConfig := stats.config.NotificationConfig.ScoringAlerts
+	if !alertsConfig.Enabled || transition.first || transition.rejected || transition.corrected {
+		return nil, current
+	}
+	isInProgress := transition.to == StateLive || transition.to == StateIntermission
+	if !isInProgress {
+		return nil, current
+	}
+
+	homeTeam, visitingTeam := stats.getTeamNames(*item)
+	gameData := stats.messageData(game, item)
+	render := func(key string, data source.MessageData) bodyBuilder {
+		return func(messages map[string]string) string {
+			return source.RenderMessage(messages, key, data)
+		}
+	}
+	previous := transition.previous
+	var alerts []scoringAlert
+
+	//touchdowns and field goals
+	if game.GetPath() == "football" {
+		homeKind := getFootballScoringKind(game.GetHomeScore() - previous.homeScore)
+		if len(homeKind) > 0 {
+			data := gameData
+			data.Team = homeTeam
+			alerts = append(alerts, scoringAlert{kind: homeKind, team: "H", body: render(homeKind+"_format", data)})
+		}
+		visitingKind := getFootballScoringKind(game.GetVisitingScore() - previous.visitingScore)
+		if len(visitingKind) > 0 {
+			data := gameData
+			data.Team = visitingTeam
+			alerts = append(alerts, scoringAlert{kind: visitingKind, team: "V", body: render(visitingKind+"_format", data)})
+		}
+	}
+
+	//lead changes - a tie in between does not reset the leader
+	leader := getLeader(game)
+	if previous.leader != 0 && leader != 0 && leader != previous.leader {
+		leaderTeam, leaderCode := homeTeam, "H"
+		if leader < 0 {
+			leaderTeam, leaderCode = visitingTeam, "V"
+		}
+		data := gameData
+		data.Team = leaderTeam
+		alerts = append(alerts, scoringAlert{kind: "lead_change", team: leaderCode, body: render("lead_change_format", data)})
+	}
+
+	//end of quarter or half
+	periodEnded := previous.period
+	if previous.timed {
+		if transition.from != StateLive || transition.to != StateIntermission {
+			periodEnded = 0
+		}
+	} else if game.GetPeriod() <= previous.period {
+		periodEnded = 0
+	}
+	if periodEnded > 0 {
+		data := gameData
+		data.Period, data.Overtime = periodEnded, isOvertime(game.GetPath(), periodEnded)
+		alerts = append(alerts, scoringAlert{kind: "end_of_period", body: render("end_of_period_format", data)})
+	}
+
+	//overtime start
+	regulation := getRegulationPeriods(game.GetPath())
+	if regulation > 0 && !current.overtimeNotified && game.GetPeriod() > regulation {
+		current.overtimeNotified = true
+		alerts = append(alerts, scoringAlert{kind: "overtime", body: render("overtime_started_msg", gameData)})
+	}
+
+	//close game in the last minutes
+	margin := game.GetHomeScore() - game.GetVisitingScore()
+	if margin < 0 {
+		margin = -margin
+	}
+	clock := game.GetClockSeconds()
+	isLastMinutes := regulation > 0 && game.GetPeriod() >= regulation && clock > 0 && clock <= alertsConfig.CloseGameMinutes*60
+	if isLastMinutes && margin <= alertsConfig.CloseGameMargin && !current.closeGameNotified {
+		current.closeGameNotified = true
+		data := gameData
+		data.Remaining = fmt.Sprintf("%d:%02d", clock/60, clock%60)
+		alerts = append(alerts, scoringAlert{kind: "close_game", body: render("close_game_format", data)})
+	}
+
+	//the alerts are sent once for the play - the team, the score change, the period, the clock and the score tell the plays apart
+	for i := range alerts {
+		alerts[i].key = fmt.Sprintf("%s.%d.%s.%s.%d.%d.%d.%d-%d", game.GetPath(), game.GetGameID(), alerts[i].kind, alerts[i].team,
+			current.scoreChanges, game.GetPeriod(), clock, game.GetHomeScore(), game.GetVisitingScore())
+	}
+	return alerts, current
+}
+
+// getFootballScoringKind gives the scoring play for the points scored since the last snapshot
+func getFootballScoringKind(points int) string {
+	switch points {
+	case 6, 7, 8:
+		return "touchdown"
+	case 3:
+		return "field_goal"
+	default:
+		return ""
+	}
+}
+
+// getRegulationPeriods gives the number of the regular periods or 0 if it is unknown for the sport
+func getRegulationPeriods(sport string) int {
+	switch sport {
+	case "football", "wbball":
+		return 4
+	case "mbball":
+		return 2
+	default:
+		return 0
+	}
+}
+
+// isOvertime checks if the period is after the regular periods of the sport
+func isOvertime(sport string, period int) bool {
+	regulation := getRegulationPeriods(sport)
+	return regulation > 0 && period > regulation
+}
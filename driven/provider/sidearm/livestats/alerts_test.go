@@ -0,0 +1,162 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package livestats
+
+import (
+	"sport/driven/provider/sidearm/livestats/source"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strings"
+	"testing"
+)
+
+// alertsConfig gives the default config with the scoring alerts enabled
+func alertsConfig() source.Config {
+	config := source.NewConfig()
+	config.NotificationConfig.ScoringAlerts.Enabled = true
+	return config
+}
+
+func TestDetectScoringAlerts(t *testing.T) {
+	type step struct {
+		game   *testGame
+		alerts []string // kind and team of the alerts
+	}
+	tests := []struct {
+		name   string
+		config source.Config
+		steps  []step
+	}{
+		{"disabled by default", source.NewConfig(), []step{
+			{game: snapshot(0, 0, 1, 900)},
+			{game: snapshot(7, 0, 1, 800)},
+		}},
+		{"no alerts for the first snapshot", alertsConfig(), []step{
+			{game: snapshot(7, 0, 1, 800)},
+		}},
+		{"home touchdown", alertsConfig(), []step{
+			{game: snapshot(0, 0, 1, 900)},
+			{game: snapshot(7, 0, 1, 800), alerts: []string{"touchdown H"}},
+		}},
+		{"visiting field goal", alertsConfig(), []step{
+			{game: snapshot(7, 0, 1, 800)},
+			{game: snapshot(7, 3, 1, 700), alerts: []string{"field_goal V"}},
+		}},
+		{"safety is not a scoring alert", alertsConfig(), []step{
+			{game: snapshot(7, 0, 1, 800)},
+			{game: snapshot(7, 2, 1, 700)},
+		}},
+		{"lead change", alertsConfig(), []step{
+			{game: snapshot(7, 0, 1, 800)},
+			{game: snapshot(7, 10, 2, 700), alerts: []string{"lead_change V"}},
+		}},
+		{"lead change after a tie", alertsConfig(), []step{
+			{game: snapshot(7, 0, 1, 800)},
+			{game: snapshot(7, 7, 1, 700), alerts: []string{"touchdown V"}},
+			{game: snapshot(7, 14, 1, 600), alerts: []string{"touchdown V", "lead_change V"}},
+		}},
+		{"end of period when the clock stops at zero", alertsConfig(), []step{
+			{game: snapshot(7, 0, 1, 30)},
+			{game: snapshot(7, 0, 1, 0), alerts: []string{"end_of_period "}},
+			{game: snapshot(7, 0, 2, 900)},
+		}},
+		{"overtime once", alertsConfig(), []step{
+			{game: snapshot(21, 21, 4, 600)},
+			{game: snapshot(21, 21, 5, 900), alerts: []string{"overtime "}},
+			{game: snapshot(21, 21, 5, 800)},
+		}},
+		{"close game once", alertsConfig(), []step{
+			{game: snapshot(13, 10, 4, 300)},
+			{game: snapshot(13, 10, 4, 100), alerts: []string{"close_game "}},
+			{game: snapshot(13, 10, 4, 90)},
+		}},
+		{"no close game with a bigger margin", alertsConfig(), []step{
+			{game: snapshot(21, 10, 4, 300)},
+			{game: snapshot(21, 10, 4, 100)},
+		}},
+		{"no alerts for a correction", alertsConfig(), []step{
+			{game: snapshot(7, 0, 1, 800)},
+			{game: snapshot(6, 0, 1, 700)},
+		}},
+		{"no alerts for the end of the game", alertsConfig(), []step{
+			{game: snapshot(21, 14, 4, 10)},
+			{game: finalSnapshot(28, 14)},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stats := &livestats{config: test.config, teamName: "Illinois", states: make(map[int]*gameTracker)}
+			item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football", Home: true, OpponentName: "Iowa"}
+			for i, step := range test.steps {
+				transition := stats.updateGameState(step.game, item)
+				var alerts []string
+				for _, alert := range transition.alerts {
+					alerts = append(alerts, alert.kind+" "+alert.team)
+				}
+				if strings.Join(alerts, ",") != strings.Join(step.alerts, ",") {
+					t.Errorf("step %d: alerts %v, expected %v", i, alerts, step.alerts)
+				}
+			}
+		})
+	}
+}
+
+func TestScoringAlertBodyAndKey(t *testing.T) {
+	stats := &livestats{config: alertsConfig(), teamName: "Illinois", states: make(map[int]*gameTracker)}
+	item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football", Home: true, OpponentName: "Iowa"}
+	messages := stats.config.NotificationConfig.GetMessages(stats.config.NotificationConfig.DefaultLocale)
+	stats.updateGameState(snapshot(0, 0, 1, 900), item)
+
+	//the same kind of alert for every touchdown has its own key
+	tests := []struct {
+		game *testGame
+		body string
+	}{
+		{snapshot(7, 0, 1, 800), "Touchdown Illinois!"},
+		{snapshot(7, 7, 2, 800), "Touchdown Iowa!"},
+		{snapshot(14, 7, 3, 800), "Touchdown Illinois!"},
+		{snapshot(14, 14, 4, 800), "Touchdown Iowa!"},
+	}
+	keys := make(map[string]bool)
+	for _, test := range tests {
+		transition := stats.updateGameState(test.game, item)
+		if len(transition.alerts) != 1 {
+			t.Fatalf("alerts %+v, expected one touchdown", transition.alerts)
+		}
+		alert := transition.alerts[0]
+		if keys[alert.key] {
+			t.Errorf("the key %s is used for another touchdown", alert.key)
+		}
+		keys[alert.key] = true
+		if body := alert.body(messages); !strings.HasPrefix(body, test.body) {
+			t.Errorf("body %s, expected to start with %s", body, test.body)
+		}
+	}
+}
+
+func TestDetectScoringAlertsKeepsTheTracker(t *testing.T) {
+	stats := &livestats{config: alertsConfig(), teamName: "Illinois", states: make(map[int]*gameTracker)}
+	item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football", Home: true, OpponentName: "Iowa"}
+	stats.updateGameState(snapshot(21, 21, 4, 600), item)
+	tracker := *stats.states[1001]
+
+	transition := stateTransition{from: StateLive, to: StateLive, previous: tracker, current: tracker}
+	alerts, current := stats.detectScoringAlerts(transition, snapshot(21, 21, 5, 900), item)
+	if len(alerts) != 1 || !current.overtimeNotified {
+		t.Errorf("alerts %+v tracker %+v, expected the overtime", alerts, current)
+	}
+	if stats.states[1001].overtimeNotified {
+		t.Error("the detection has changed the tracked game")
+	}
+}
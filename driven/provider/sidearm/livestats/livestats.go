@@ -32,20 +32,25 @@ type LiveStats interface {
 	ProcessLiveData(items []*sidearmModel.LiveGameItem) error
 	IsDuringLiveGame() bool
 	LiveData() []model.LiveGame
+	MessageData(item *sidearmModel.LiveGameItem) source.MessageData
+	BoxScore(gameID int) *model.GameBoxScore
+	Plays(gameID int, since int) *model.GamePlays
+	HealthEvents() []source.HealthEvent
 }
 
 type livestats struct {
-	config        source.Config
-	notifications notifications.Notifications
-	games         sidearmModel.GameItems
-	lsSource      source.Source
-	teamName      string
+	config   source.Config
+	sender   notifications.Sender
+	games    sidearmModel.GameItems
+	lsSource source.Source
+	teamName string
+	states   map[int]*gameTracker
 }
 
 // New create live stats checker
-func New(notifications notifications.Notifications, config source.Config, ftpHost string, ftpUser string, ftpPassword string, teamName string) LiveStats {
-	lsSource := source.New(config, ftpHost, ftpUser, ftpPassword)
-	return &livestats{config: config, notifications: notifications, lsSource: lsSource, teamName: teamName}
+func New(sender notifications.Sender, config source.Config, ftpHost string, ftpUser string, ftpPassword string, teamName string, storage notifications.Storage) LiveStats {
+	lsSource := source.New(config, ftpHost, ftpUser, ftpPassword, storage)
+	return &livestats{config: config, sender: sender, lsSource: lsSource, teamName: teamName, states: make(map[int]*gameTracker)}
 }
 
 func (stats *livestats) UpdateConfig(config source.Config) {
@@ -62,6 +67,8 @@ func (stats *livestats) ProcessLiveData(items []*sidearmModel.LiveGameItem) erro
 	for _, item := range items {
 		stats.processLiveDataForItem(item)
 	}
+	stats.pruneStates(items)
+	stats.lsSource.PruneGames(items)
 	return nil
 }
 
@@ -86,8 +93,8 @@ func (stats *livestats) processLiveDataForItem(item *sidearmModel.LiveGameItem)
 	//2. check if we need game changed notification
 	needsGameChangedNotification := stats.needsGameChangedNotification(loadedGameItem)
 
-	//3. check if we need game state changed notification
-	needsGameStateChangedNotification, gameStarted := stats.needsGameStateChangedNotification(loadedGameItem)
+	//3. move the game to its new state
+	transition := stats.updateGameState(loadedGameItem, item)
 
 	//4. update it to the list
 	foundedGameItem, foundIndex := stats.findGame(loadedGameItem)
@@ -110,18 +117,42 @@ func (stats *livestats) processLiveDataForItem(item *sidearmModel.LiveGameItem)
 	}
 
 	//6. send game state changed notification if needed
-	if needsGameStateChangedNotification {
-		log.Printf("LiveStats: processLiveDataForItem -> needs user notification - %d\n", gameID)
-		stats.notifyGameStateChanged(loadedGameItem, item, gameStarted)
+	if transition.needsGameStartedNotification() {
+		log.Printf("LiveStats: processLiveDataForItem -> needs game started notification - %d\n", gameID)
+		stats.notifyGameStateChanged(loadedGameItem, item, true)
+	} else if transition.needsGameEndedNotification() {
+		log.Printf("LiveStats: processLiveDataForItem -> needs game ended notification - %d\n", gameID)
+		stats.notifyGameStateChanged(loadedGameItem, item, false)
+	} else if transition.corrected {
+		log.Printf("LiveStats: processLiveDataForItem -> needs score correction notification - %d\n", gameID)
+		stats.notifyGameCorrected(loadedGameItem, item)
 	} else {
 		log.Printf("LiveStats: processLiveDataForItem -> do not need user notification - %d\n", gameID)
 	}
+
+	//7. send scoring alerts if needed
+	for _, alert := range transition.alerts {
+		log.Printf("LiveStats: processLiveDataForItem -> needs %s notification - %d\n", alert.kind, gameID)
+		stats.sendGameNotification(loadedGameItem, item, alert.kind, alert.body, alert.key)
+	}
 }
 
 func (stats *livestats) LiveData() []model.LiveGame {
 	return stats.games.Games
 }
 
+func (stats *livestats) BoxScore(gameID int) *model.GameBoxScore {
+	return stats.lsSource.BoxScore(gameID)
+}
+
+func (stats *livestats) Plays(gameID int, since int) *model.GamePlays {
+	return stats.lsSource.Plays(gameID, since)
+}
+
+func (stats *livestats) HealthEvents() []source.HealthEvent {
+	return stats.lsSource.HealthEvents()
+}
+
 func (stats *livestats) IsDuringLiveGame() bool {
 	if stats.games.Games == nil || len(stats.games.Games) == 0 {
 		//no games
@@ -150,29 +181,6 @@ func (stats *livestats) needsGameChangedNotification(newGame model.LiveGame) boo
 	return false
 }
 
-func (stats *livestats) needsGameStateChangedNotification(newGame model.LiveGame) (needsNotification bool, gameStarted bool) {
-	foundedGame, _ := stats.findGame(newGame)
-	newGameStarted := (foundedGame == nil) && (newGame != nil) && (newGame.GetHasStarted() == true)
-	if newGameStarted {
-		//It needs game state notification if we receive new game with hasStarted = true
-		return true, true
-	} else if foundedGame == nil {
-		//It does not need game state notification if new game is not started
-		return false, false
-	}
-	hasJustStarted := (((foundedGame.GetHasStarted() == false) && (foundedGame.GetIsComplete() == false)) && ((newGame.GetHasStarted() == true) && (newGame.GetIsComplete() == false)))
-	if hasJustStarted {
-		//it needs game state changed notification because the game has just started
-		return true, true
-	}
-	hasJustFinished := ((foundedGame.GetHasStarted() == true) && (newGame.GetHasStarted() == true) && (foundedGame.GetIsComplete() == false) && (newGame.GetIsComplete() == true))
-	if hasJustFinished {
-		//it needs game state notification because the game has just finished
-		return true, false
-	}
-	return false, false
-}
-
 func (stats *livestats) findGame(newGame model.LiveGame) (model.LiveGame, int) {
 	if stats.games.Games == nil || len(stats.games.Games) == 0 {
 		return nil, -1
@@ -188,7 +196,7 @@ func (stats *livestats) findGame(newGame model.LiveGame) (model.LiveGame, int) {
 func (stats *livestats) notifyGameChanged(game model.LiveGame) {
 	path := game.GetPath()
 	data := game.Encode()
-	err := stats.notifications.SendDataMsg(path, data)
+	err := stats.sender.SendData(path, data)
 	if err != nil {
 		log.Printf("LiveStats: notifyGameChanged -> error sending notification topic:%s data:%s %s", path, data, err.Error())
 	} else {
@@ -196,51 +204,113 @@ func (stats *livestats) notifyGameChanged(game model.LiveGame) {
 	}
 }
 
+// bodyBuilder builds the notification body from the messages of a locale
+type bodyBuilder func(messages map[string]string) string
+
 // build notification using configuration and send
 func (stats *livestats) notifyGameStateChanged(game model.LiveGame, item *sidearmModel.LiveGameItem, started bool) {
-	homeTeam, visitingTeam := stats.getTeamNames(*item)
-	configGameMessages := stats.config.NotificationConfig.Messages
-	msgTitleFormat := configGameMessages["game_title_format"]
-	if len(msgTitleFormat) == 0 {
-		msgTitleFormat = ""
-	}
-	// build notification title
-	title := fmt.Sprintf(msgTitleFormat, homeTeam, visitingTeam)
-
 	// build body content
-	var body string
+	var body bodyBuilder
 	var gameState string
 	if started {
 		gameState = "start"
 		// Game started content
-		body = configGameMessages["game_started_msg"]
+		body = func(messages map[string]string) string {
+			return source.RenderMessage(messages, "game_started_msg", stats.messageData(game, item))
+		}
 	} else {
 		gameState = "end"
 		// Game ended content
-		gameEndedMsg := configGameMessages["game_ended_msg"]
-		if len(gameEndedMsg) == 0 {
-			gameEndedMsg = ""
+		body = func(messages map[string]string) string {
+			data := stats.messageData(game, item)
+			//the result variant is used if it is configured
+			key := fmt.Sprintf("game_ended_%s_msg", data.Result)
+			if _, exists := messages[key]; !exists {
+				key = "game_ended_msg"
+			}
+			return source.RenderMessage(messages, key, data)
 		}
+	}
+	key := fmt.Sprintf("%s.%d.%s", game.GetPath(), game.GetGameID(), gameState)
+	stats.sendGameNotification(game, item, gameState, body, key)
+}
 
-		gameEndedFormat := configGameMessages["game_ended_score_format"]
-		if len(gameEndedFormat) == 0 {
-			gameEndedFormat = ""
-		}
-		gameEndedScore := fmt.Sprintf(gameEndedFormat, homeTeam, game.GetHomeScore(), visitingTeam, game.GetVisitingScore())
-		body = fmt.Sprintf("%s %s", gameEndedMsg, gameEndedScore)
+// build score correction notification using configuration and send
+func (stats *livestats) notifyGameCorrected(game model.LiveGame, item *sidearmModel.LiveGameItem) {
+	body := func(messages map[string]string) string {
+		return source.RenderMessage(messages, "game_corrected_msg", stats.messageData(game, item))
+	}
+	key := fmt.Sprintf("%s.%d.correction.%d-%d", game.GetPath(), game.GetGameID(), game.GetHomeScore(), game.GetVisitingScore())
+	stats.sendGameNotification(game, item, "correction", body, key)
+}
+
+// sendGameNotification sends notification with the game title to the "athletics.{path}.notification.{kind}" topic only once for the key.
+// The fans following only this game get it from the "athletics.{path}.game.{id}.notification.{kind}" topic.
+// It is sent in every configured locale, the topics of the other than the default locale are tagged with the locale.
+func (stats *livestats) sendGameNotification(game model.LiveGame, item *sidearmModel.LiveGameItem, kind string, body bodyBuilder, key string) {
+	notificationConfig := stats.config.NotificationConfig
+	topics := []string{fmt.Sprintf("athletics.%s.notification.%s", game.GetPath(), kind)}
+	if notificationConfig.PerGameTopics {
+		topics = append(topics, fmt.Sprintf("athletics.%s.game.%d.notification.%s", game.GetPath(), game.GetGameID(), kind))
 	}
-	// topic is "athletics.{path}.notification.{start|end}"
-	topic := fmt.Sprintf("athletics.%s.notification.%s", game.GetPath(), gameState)
 	data := make((map[string]string))
 	data["GameId"] = strconv.Itoa(game.GetGameID())
 	data["Path"] = game.GetPath()
 	data["click_action"] = "FLUTTER_NOTIFICATION_CLICK"
-	err := stats.notifications.SendNotificationMsg(topic, title, body, data)
-	if err != nil {
-		log.Printf("LiveStats: notifyGameStateChanged -> error sending notification topic:%s title:%s body:%s data:%s %s", topic, title, body, data, err.Error())
+
+	for _, locale := range notificationConfig.GetLocales() {
+		messages := notificationConfig.GetMessages(locale)
+		// build notification title
+		title := source.RenderMessage(messages, "game_title_format", stats.messageData(game, item))
+		msgBody := body(messages)
+		for i, topic := range topics {
+			msgKey := key
+			if i > 0 {
+				msgKey = fmt.Sprintf("%s.game", key)
+			}
+			msg := notifications.Message{Topic: topic, Title: title, Body: msgBody, Data: data, Key: msgKey, Live: true, Locale: notificationConfig.GetTopicLocale(locale)}
+			err := stats.sender.SendNotification(msg)
+			if err != nil {
+				log.Printf("LiveStats: sendGameNotification -> error sending notification topic:%s locale:%s title:%s body:%s data:%s %s", topic, locale, title, msgBody, data, err.Error())
+			} else {
+				log.Printf("LiveStats: sendGameNotification -> success sending notification topic:%s locale:%s title:%s body:%s data:%s", topic, locale, title, msgBody, data)
+			}
+		}
+	}
+}
+
+// MessageData gives the fields for the message templates of the game including the current score if the game is live
+func (stats *livestats) MessageData(item *sidearmModel.LiveGameItem) source.MessageData {
+	gameID, _ := strconv.Atoi(item.GameID)
+	for _, game := range stats.LiveData() {
+		if game.GetGameID() == gameID {
+			return stats.messageData(game, item)
+		}
+	}
+	homeTeam, visitingTeam := stats.getTeamNames(*item)
+	return source.MessageData{Sport: item.Sport, HomeTeam: homeTeam, VisitingTeam: visitingTeam, Opponent: item.OpponentName, OurTeam: stats.teamName, Result: "tie"}
+}
+
+// messageData gives the fields of the game for the message templates
+func (stats *livestats) messageData(game model.LiveGame, item *sidearmModel.LiveGameItem) source.MessageData {
+	homeTeam, visitingTeam := stats.getTeamNames(*item)
+	data := source.MessageData{Sport: game.GetPath(), HomeTeam: homeTeam, VisitingTeam: visitingTeam, Opponent: item.OpponentName,
+		OurTeam: stats.teamName, HomeScore: game.GetHomeScore(), VisitingScore: game.GetVisitingScore()}
+	data.OurScore, data.OpponentScore = game.GetHomeScore(), game.GetVisitingScore()
+	if !item.Home {
+		data.OurScore, data.OpponentScore = game.GetVisitingScore(), game.GetHomeScore()
+	}
+	if data.OurScore > data.OpponentScore {
+		data.Result = "win"
+	} else if data.OurScore < data.OpponentScore {
+		data.Result = "loss"
 	} else {
-		log.Printf("LiveStats: notifyGameStateChanged -> success sending notification topic:%s title:%s body:%s data:%s", topic, title, body, data)
+		data.Result = "tie"
+	}
+	if game.GetPeriod() > 0 {
+		data.Period, data.Overtime = game.GetPeriod(), isOvertime(game.GetPath(), game.GetPeriod())
 	}
+	return data
 }
 
 func (stats *livestats) getTeamNames(item sidearmModel.LiveGameItem) (string, string) {
@@ -0,0 +1,138 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package livestats
+
+import (
+	"fmt"
+	"sport/core/model"
+	"sport/driven/notifications"
+	"sport/driven/provider/sidearm/livestats/source"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSource gives the snapshots of the game in order, one for every load
+type fakeSource struct {
+	snapshots []model.LiveGame
+	loaded    int
+}
+
+func (s *fakeSource) UpdateConfig(config source.Config) {}
+
+func (s *fakeSource) LoadData(item *sidearmModel.LiveGameItem) (model.LiveGame, error) {
+	if s.loaded >= len(s.snapshots) {
+		return nil, fmt.Errorf("no more snapshots for %s", item.GameID)
+	}
+	game := s.snapshots[s.loaded]
+	s.loaded++
+	return game, nil
+}
+
+func (s *fakeSource) HealthEvents() []source.HealthEvent            { return nil }
+func (s *fakeSource) PruneGames(items []*sidearmModel.LiveGameItem) {}
+func (s *fakeSource) BoxScore(gameID int) *model.GameBoxScore       { return nil }
+func (s *fakeSource) Plays(gameID int, since int) *model.GamePlays  { return nil }
+
+// localesConfig gives the default config with the locales and the topics of the test
+func localesConfig(locales []string, perGameTopics bool, scoringAlerts bool) source.Config {
+	config := source.NewConfig()
+	config.NotificationConfig.Locales = locales
+	config.NotificationConfig.PerGameTopics = perGameTopics
+	config.NotificationConfig.ScoringAlerts.Enabled = scoringAlerts
+	return config
+}
+
+func TestProcessLiveDataSendsNotifications(t *testing.T) {
+	game := []model.LiveGame{notStarted(), snapshot(0, 0, 1, 900), snapshot(7, 0, 1, 800), finalSnapshot(7, 0), finalSnapshot(7, 0)}
+	tests := []struct {
+		name     string
+		config   source.Config
+		expected []string // topic and body of the recorded notifications
+	}{
+		{"game start and end", localesConfig([]string{"en"}, false, false), []string{
+			"athletics.football.notification.start|The Game has started",
+			"athletics.football.notification.end|Illinois wins 7-0 over Iowa!",
+		}},
+		{"scoring alerts", localesConfig([]string{"en"}, false, true), []string{
+			"athletics.football.notification.start|The Game has started",
+			"athletics.football.notification.touchdown|Touchdown Illinois!",
+			"athletics.football.notification.end|Illinois wins 7-0 over Iowa!",
+		}},
+		{"per game topics", localesConfig([]string{"en"}, true, false), []string{
+			"athletics.football.notification.start|The Game has started",
+			"athletics.football.game.1001.notification.start|The Game has started",
+			"athletics.football.notification.end|Illinois wins 7-0 over Iowa!",
+			"athletics.football.game.1001.notification.end|Illinois wins 7-0 over Iowa!",
+		}},
+		{"locales", localesConfig([]string{"en", "es", "zh"}, false, false), []string{
+			"athletics.football.notification.start|The Game has started",
+			"athletics.football.notification.start.es|El partido ha comenzado",
+			"athletics.football.notification.start.zh|比赛已开始",
+			"athletics.football.notification.end|Illinois wins 7-0 over Iowa!",
+			"athletics.football.notification.end.es|¡Illinois gana 7-0 a Iowa!",
+			"athletics.football.notification.end.zh|Illinois 以 7-0 战胜 Iowa！",
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := notifications.NewRecorder()
+			outbox := notifications.NewOutbox(recorder, nil, test.config.NotificationConfig.Outbox)
+			outbox.Start()
+			dispatcher := notifications.NewDispatcher(outbox, nil, notifications.DispatcherConfig{})
+			stats := &livestats{config: test.config, sender: dispatcher, lsSource: &fakeSource{snapshots: game}, teamName: "Illinois",
+				states: make(map[int]*gameTracker)}
+
+			item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football", Home: true, OpponentName: "Iowa"}
+			for range game {
+				if err := stats.ProcessLiveData([]*sidearmModel.LiveGameItem{item}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			deadline := time.Now().Add(2 * time.Second)
+			for len(outbox.GetOutbox().Pending) > 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			var sent []string
+			var lastData map[string]string
+			for _, msg := range recorder.Messages() {
+				if msg.Type == "data" {
+					if msg.Topic != "football" {
+						t.Errorf("data message to the topic %s", msg.Topic)
+					}
+					lastData = msg.Data
+					continue
+				}
+				if msg.Title != "Illinois vs Iowa" {
+					t.Errorf("title %s for the topic %s", msg.Title, msg.Topic)
+				}
+				sent = append(sent, msg.Topic+"|"+msg.Body)
+			}
+			if len(sent) != len(test.expected) {
+				t.Fatalf("sent %v, expected %v", sent, test.expected)
+			}
+			for i := range sent {
+				if !strings.HasPrefix(sent[i], test.expected[i]) {
+					t.Errorf("notification %d is %s, expected %s", i, sent[i], test.expected[i])
+				}
+			}
+			if lastData["HomeScore"] != "7" || lastData["IsComplete"] != "true" {
+				t.Errorf("the last data message %v is not the final score", lastData)
+			}
+		})
+	}
+}
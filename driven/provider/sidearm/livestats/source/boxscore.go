@@ -0,0 +1,178 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"sync"
+	"time"
+)
+
+// how many games keep their last box score
+const maxBoxScores = 50
+
+// xmlTeamStats is the team box score of the stat crew xml, it has the same structure for all the sports
+type xmlTeamStats struct {
+	VH      string           `xml:"vh,attr"`
+	ID      string           `xml:"id,attr"`
+	Name    string           `xml:"name,attr"`
+	Totals  xmlTotals        `xml:"totals"`
+	Players []xmlPlayerStats `xml:"player"`
+}
+
+type xmlTotals struct {
+	Attrs []xml.Attr `xml:",any,attr"`
+	xmlStats
+}
+
+type xmlPlayerStats struct {
+	Uni       string `xml:"uni,attr"`
+	Name      string `xml:"name,attr"`
+	Checkname string `xml:"checkname,attr"`
+	GS        string `xml:"gs,attr"`
+	Pos       string `xml:"pos,attr"`
+	OPos      string `xml:"opos,attr"` // football
+	DPos      string `xml:"dpos,attr"` // football
+	xmlStats
+}
+
+// xmlStats contains the stats elements like <stats fgm="3" fga="7"/> or <rush att="12" yds="87"/>
+type xmlStats struct {
+	Groups []xmlStatsGroup `xml:",any"`
+}
+
+type xmlStatsGroup struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+}
+
+// boxScores keeps the last box score of the latest games, the final box scores are persisted so they are kept after
+// they are removed from the memory and after restart
+type boxScores struct {
+	mu      sync.Mutex
+	storage Storage
+	games   map[int]*model.GameBoxScore
+}
+
+func newBoxScores(storage Storage) *boxScores {
+	return &boxScores{storage: storage, games: make(map[int]*model.GameBoxScore)}
+}
+
+func (scores *boxScores) set(gameID int, boxScore *model.GameBoxScore) {
+	scores.mu.Lock()
+	previous := scores.games[gameID]
+	scores.games[gameID] = boxScore
+	if len(scores.games) > maxBoxScores {
+		//remove the box score which has not been updated for the longest time
+		oldestID := gameID
+		for id, game := range scores.games {
+			if game.UpdatedAt.Before(scores.games[oldestID].UpdatedAt) {
+				oldestID = id
+			}
+		}
+		delete(scores.games, oldestID)
+	}
+	scores.mu.Unlock()
+
+	isChanged := previous == nil || !previous.IsComplete || !reflect.DeepEqual(previous.Teams, boxScore.Teams)
+	if boxScore.IsComplete && isChanged {
+		err := scores.storage.SaveState(boxScoreStateName(gameID), boxScore)
+		if err != nil {
+			log.Printf("source: boxScores -> failed to save the final box score of game %d. Reason: %s", gameID, err.Error())
+		}
+	}
+}
+
+func (scores *boxScores) get(gameID int) *model.GameBoxScore {
+	scores.mu.Lock()
+	boxScore := scores.games[gameID]
+	scores.mu.Unlock()
+	if boxScore != nil {
+		return boxScore
+	}
+
+	//the final box score of a game which is not in the memory
+	var saved model.GameBoxScore
+	err := scores.storage.LoadState(boxScoreStateName(gameID), &saved)
+	if err != nil || len(saved.GameID) == 0 {
+		return nil
+	}
+	return &saved
+}
+
+func boxScoreStateName(gameID int) string {
+	return fmt.Sprintf("boxscore_%d", gameID)
+}
+
+// buildBoxScore builds the box score of the game from the teams of the stat crew xml
+func buildBoxScore(item *sidearmModel.LiveGameItem, teams []xmlTeamStats, isComplete bool) *model.GameBoxScore {
+	boxScore := model.GameBoxScore{GameID: item.GameID, Sport: item.Sport, IsComplete: isComplete, UpdatedAt: time.Now(), Teams: []model.BoxScoreTeam{}}
+	for _, team := range teams {
+		home := team.VH == "H"
+		totals := buildStats(team.Totals.xmlStats)
+		if len(team.Totals.Attrs) > 0 {
+			totals["totals"] = buildStatsGroup(team.Totals.Attrs)
+		}
+		boxTeam := model.BoxScoreTeam{Code: team.ID, Name: team.Name, Home: home, OurTeam: home == item.Home, Totals: totals, Players: []model.BoxScorePlayer{}}
+		for _, player := range team.Players {
+			if player.Checkname == "TEAM" {
+				//the team stats are not for a player
+				continue
+			}
+			boxTeam.Players = append(boxTeam.Players, model.BoxScorePlayer{Uni: player.Uni, Name: player.Name,
+				Position: getPlayerPosition(player), Starter: player.GS == "1", Stats: buildStats(player.xmlStats)})
+		}
+		boxScore.Teams = append(boxScore.Teams, boxTeam)
+	}
+	//the home team is the second one like in the score
+	sort.SliceStable(boxScore.Teams, func(i, j int) bool {
+		return !boxScore.Teams[i].Home && boxScore.Teams[j].Home
+	})
+	return &boxScore
+}
+
+func buildStats(stats xmlStats) model.BoxScoreStats {
+	result := model.BoxScoreStats{}
+	for _, group := range stats.Groups {
+		if len(group.Attrs) > 0 {
+			result[group.XMLName.Local] = buildStatsGroup(group.Attrs)
+		}
+	}
+	return result
+}
+
+func buildStatsGroup(attrs []xml.Attr) map[string]string {
+	group := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		group[attr.Name.Local] = attr.Value
+	}
+	return group
+}
+
+func getPlayerPosition(player xmlPlayerStats) string {
+	if len(player.Pos) > 0 {
+		return player.Pos
+	}
+	if len(player.OPos) > 0 {
+		return player.OPos
+	}
+	return player.DPos
+}
@@ -0,0 +1,210 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"testing"
+	"time"
+)
+
+// memoryStorage keeps the saved states in the memory like the storage adapter keeps them in files
+type memoryStorage struct {
+	states map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{states: make(map[string][]byte)}
+}
+
+func (storage *memoryStorage) LoadState(name string, value interface{}) error {
+	data, ok := storage.states[name]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(data, value)
+}
+
+func (storage *memoryStorage) SaveState(name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	storage.states[name] = data
+	return nil
+}
+
+// replayConfig gives the default config without the date checks as the recorded xml is for a past date
+func replayConfig() Config {
+	config := NewConfig()
+	config.FootballConfig.XMLDateCheck = false
+	config.MBasketballConfig.XMLDateCheck = false
+	config.WBasketballConfig.XMLDateCheck = false
+	config.VolleyballConfig.XMLDateCheck = false
+	return config
+}
+
+// replayItem gives a game which has started an hour ago, so it is not complete just because of its time
+func replayItem(sport string) *sidearmModel.LiveGameItem {
+	return &sidearmModel.LiveGameItem{GameID: "1001", Sport: sport, Home: true, Time: time.Now().Add(-time.Hour)}
+}
+
+// replay passes the recorded xml files in their order to the load function like they were downloaded one by one
+func replay(t *testing.T, load func(xmlData []byte) (model.LiveGame, error), files ...string) []model.LiveGame {
+	t.Helper()
+	games := make([]model.LiveGame, len(files))
+	for i, file := range files {
+		xmlData, err := os.ReadFile(filepath.Join("testdata", file))
+		if err != nil {
+			t.Fatalf("failed to read %s: %s", file, err)
+		}
+		games[i], err = load(xmlData)
+		if err != nil {
+			t.Fatalf("failed to load %s: %s", file, err)
+		}
+	}
+	return games
+}
+
+func assertGame(t *testing.T, file string, game model.LiveGame, homeScore int, visitingScore int, period int, isComplete bool) {
+	t.Helper()
+	if game.GetHomeScore() != homeScore || game.GetVisitingScore() != visitingScore {
+		t.Errorf("%s: score %d-%d, expected %d-%d", file, game.GetHomeScore(), game.GetVisitingScore(), homeScore, visitingScore)
+	}
+	if game.GetPeriod() != period {
+		t.Errorf("%s: period %d, expected %d", file, game.GetPeriod(), period)
+	}
+	if game.GetIsComplete() != isComplete {
+		t.Errorf("%s: complete %t, expected %t", file, game.GetIsComplete(), isComplete)
+	}
+}
+
+// assertBoxScore checks that the visiting team is the first one, our team is the home one and the TEAM player is skipped
+func assertBoxScore(t *testing.T, boxScore *model.GameBoxScore, isComplete bool, homeCode string, visitingCode string, homePlayers int, visitingPlayers int) {
+	t.Helper()
+	if boxScore == nil {
+		t.Fatal("no box score")
+	}
+	if boxScore.IsComplete != isComplete {
+		t.Errorf("box score complete %t, expected %t", boxScore.IsComplete, isComplete)
+	}
+	if len(boxScore.Teams) != 2 {
+		t.Fatalf("box score has %d teams, expected 2", len(boxScore.Teams))
+	}
+	visiting, home := boxScore.Teams[0], boxScore.Teams[1]
+	if visiting.Code != visitingCode || visiting.Home || visiting.OurTeam {
+		t.Errorf("unexpected visiting team %s home %t our team %t", visiting.Code, visiting.Home, visiting.OurTeam)
+	}
+	if home.Code != homeCode || !home.Home || !home.OurTeam {
+		t.Errorf("unexpected home team %s home %t our team %t", home.Code, home.Home, home.OurTeam)
+	}
+	if len(home.Players) != homePlayers || len(visiting.Players) != visitingPlayers {
+		t.Errorf("the teams have %d and %d players, expected %d and %d", len(home.Players), len(visiting.Players), homePlayers, visitingPlayers)
+	}
+	for _, team := range boxScore.Teams {
+		for _, player := range team.Players {
+			if player.Name == "TEAM" {
+				t.Errorf("team %s has the TEAM player", team.Code)
+			}
+		}
+	}
+}
+
+func TestFootballBoxScore(t *testing.T) {
+	storage := newMemoryStorage()
+	item := replayItem("football")
+	source := newXMLFootballSource(replayConfig(), "", "", "", newBoxScores(storage), newPlayByPlay(storage))
+	load := func(xmlData []byte) (model.LiveGame, error) {
+		return source.loadXML(item, xmlData)
+	}
+
+	games := replay(t, load, "football_1.xml")
+	assertGame(t, "football_1.xml", games[0], 14, 0, 2, false)
+	assertBoxScore(t, source.boxScores.get(1001), false, "ILL", "IOWA", 3, 3)
+
+	games = replay(t, load, "football_2.xml")
+	assertGame(t, "football_2.xml", games[0], 14, 3, 4, true)
+	boxScore := source.boxScores.get(1001)
+	assertBoxScore(t, boxScore, true, "ILL", "IOWA", 3, 4)
+	if fg := boxScore.Teams[0].Totals["fg"]; fg["made"] != "1" || fg["long"] != "38" {
+		t.Errorf("unexpected visiting field goals %v", fg)
+	}
+	if totals := boxScore.Teams[1].Totals["totals"]; totals["totoff_yards"] != "311" {
+		t.Errorf("unexpected home total offense %v", totals)
+	}
+
+	//the final box score is kept after restart
+	restarted := newXMLFootballSource(replayConfig(), "", "", "", newBoxScores(storage), newPlayByPlay(storage))
+	assertBoxScore(t, restarted.boxScores.get(1001), true, "ILL", "IOWA", 3, 4)
+}
+
+func TestBasketballBoxScore(t *testing.T) {
+	storage := newMemoryStorage()
+	item := replayItem("mbball")
+	source := newXMLBasketballSource(replayConfig(), "", "", "", newBoxScores(storage), newPlayByPlay(storage))
+	load := func(xmlData []byte) (model.LiveGame, error) {
+		return source.loadXML(item, xmlData)
+	}
+
+	games := replay(t, load, "basketball_1.xml")
+	assertGame(t, "basketball_1.xml", games[0], 42, 40, 2, false)
+	assertBoxScore(t, source.boxScores.get(1001), false, "ILL", "PUR", 2, 2)
+
+	games = replay(t, load, "basketball_2.xml")
+	assertGame(t, "basketball_2.xml", games[0], 75, 70, 2, true)
+	boxScore := source.boxScores.get(1001)
+	assertBoxScore(t, boxScore, true, "ILL", "PUR", 2, 2)
+	if stats := boxScore.Teams[0].Players[0].Stats["stats"]; boxScore.Teams[0].Players[0].Name != "Edey, Zach" || stats["tp"] != "30" {
+		t.Errorf("unexpected visiting player %+v", boxScore.Teams[0].Players[0])
+	}
+
+	//the final box score is kept after restart
+	restarted := newXMLBasketballSource(replayConfig(), "", "", "", newBoxScores(storage), newPlayByPlay(storage))
+	assertBoxScore(t, restarted.boxScores.get(1001), true, "ILL", "PUR", 2, 2)
+}
+
+func TestVolleyballBoxScore(t *testing.T) {
+	storage := newMemoryStorage()
+	item := replayItem("wvball")
+	source := newXMLVolleyballSource(replayConfig(), "", "", "", newBoxScores(storage))
+	load := func(xmlData []byte) (model.LiveGame, error) {
+		return source.loadXML(item, xmlData)
+	}
+
+	games := replay(t, load, "volleyball_1.xml", "volleyball_2.xml")
+	assertGame(t, "volleyball_1.xml", games[0], 2, 1, 4, false)
+	assertGame(t, "volleyball_2.xml", games[1], 3, 1, 4, true)
+
+	var customData volleyballCustomData
+	if err := json.Unmarshal([]byte(games[0].GetCustomData()), &customData); err != nil {
+		t.Fatalf("failed to unmarshal the custom data: %s", err)
+	}
+	if !customData.HasExtraData || customData.HPoints != "14" || customData.VPoints != "11" || customData.Serving != "H" {
+		t.Errorf("unexpected custom data %+v", customData)
+	}
+
+	boxScore := source.boxScores.get(1001)
+	assertBoxScore(t, boxScore, true, "ILL", "NEB", 1, 1)
+	if attack := boxScore.Teams[1].Players[0].Stats["attack"]; attack["k"] != "21" || boxScore.Teams[1].Players[0].Position != "OH" {
+		t.Errorf("unexpected home player %+v", boxScore.Teams[1].Players[0])
+	}
+
+	//the final box score is kept after restart
+	assertBoxScore(t, newBoxScores(storage).get(1001), true, "ILL", "NEB", 1, 1)
+}
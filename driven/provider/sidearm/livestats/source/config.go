@@ -14,19 +14,110 @@
 
 package source
 
+import (
+	"sport/driven/notifications"
+	"sport/driven/standings"
+	"time"
+)
+
 // Config structure
 type Config struct {
-	LivestatsSource    map[string]map[string][]string `json:"livestats_source"`
-	FootballConfig     FootballConfig                 `json:"football_config"`
-	MBasketballConfig  MBasketballConfig              `json:"mbball_config"`
-	WBasketballConfig  WBasketballConfig              `json:"wbball_config"`
-	VolleyballConfig   VolleyballConfig               `json:"wvball_config"`
-	NotificationConfig NotificationConfig             `json:"notification_config"`
+	LivestatsSource      map[string]map[string][]string `json:"livestats_source"`
+	FootballConfig       FootballConfig                 `json:"football_config"`
+	MBasketballConfig    MBasketballConfig              `json:"mbball_config"`
+	WBasketballConfig    WBasketballConfig              `json:"wbball_config"`
+	VolleyballConfig     VolleyballConfig               `json:"wvball_config"`
+	NotificationConfig   NotificationConfig             `json:"notification_config"`
+	SourceHealthConfig   SourceHealthConfig             `json:"source_health_config"`
+	ReconciliationConfig ReconciliationConfig           `json:"reconciliation_config"`
+	StandingsConfig      standings.Config               `json:"standings_config"`
+}
+
+// ReconciliationConfig structure
+type ReconciliationConfig struct {
+	Enabled                  bool   `json:"enabled"`
+	Policy                   string `json:"policy"` // prefer_priority, prefer_confidence or prefer_monotonic
+	RequireAgreementForFinal bool   `json:"require_agreement_for_final"`
+}
+
+// SourceHealthConfig structure
+type SourceHealthConfig struct {
+	StaleThresholdSeconds int `json:"stale_threshold_seconds"` // 0 disables the stale feed detection
 }
 
 // NotificationConfig structure
 type NotificationConfig struct {
-	Messages map[string]string `json:"messages"`
+	Messages          map[string]string              `json:"messages"`           // the messages in the default locale
+	DefaultLocale     string                         `json:"default_locale"`     // the messages in the default locale are sent to the topics without locale
+	Locales           []string                       `json:"locales"`            // the locales the notifications are sent in
+	PerGameTopics     bool                           `json:"per_game_topics"`    // send the game notifications also to the "athletics.{path}.game.{id}.notification.{kind}" topics
+	LocalizedMessages map[string]map[string]string   `json:"localized_messages"` // locale -> messages, the missing ones fall back to the default locale
+	ScoringAlerts     ScoringAlertsConfig            `json:"scoring_alerts"`
+	Reminders         RemindersConfig                `json:"reminders"`
+	Recaps            RecapsConfig                   `json:"recaps"`
+	News              NewsConfig                     `json:"news"`
+	Dispatcher        notifications.DispatcherConfig `json:"dispatcher"`
+	Outbox            notifications.OutboxConfig     `json:"outbox"`
+	Notifier          notifications.NotifierConfig   `json:"notifier"`
+}
+
+// GetLocales gives the locales the notifications are sent in. The default locale is always first.
+func (c NotificationConfig) GetLocales() []string {
+	locales := []string{c.DefaultLocale}
+	for _, locale := range c.Locales {
+		if locale != c.DefaultLocale && indexOf(locales, locale) == -1 {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// GetMessages gives the messages for the locale. The missing translations fall back to the default locale.
+func (c NotificationConfig) GetMessages(locale string) map[string]string {
+	messages := make(map[string]string, len(c.Messages))
+	for key, message := range c.Messages {
+		messages[key] = message
+	}
+	for key, message := range c.LocalizedMessages[locale] {
+		if len(message) > 0 {
+			messages[key] = message
+		}
+	}
+	return messages
+}
+
+// GetTopicLocale gives the locale tag of the topics - empty for the default locale
+func (c NotificationConfig) GetTopicLocale(locale string) string {
+	if locale == c.DefaultLocale {
+		return ""
+	}
+	return locale
+}
+
+// RemindersConfig structure
+type RemindersConfig struct {
+	Enabled        bool  `json:"enabled"`
+	OffsetsMinutes []int `json:"offsets_minutes"` // the reminders are sent these minutes before the game start
+}
+
+// RecapsConfig structure
+type RecapsConfig struct {
+	Enabled             bool `json:"enabled"`
+	MaxAgeHours         int  `json:"max_age_hours"`          // no recaps for the games which started before
+	BoxScoreWaitMinutes int  `json:"box_score_wait_minutes"` // how long to wait for the recap story before sending only the box score
+}
+
+// NewsConfig structure
+type NewsConfig struct {
+	MaxPushesPerCheck int  `json:"max_pushes_per_check"` // more new stories for a sport are sent as one digest, 0 means no limit
+	NotifyEdits       bool `json:"notify_edits"`
+}
+
+// ScoringAlertsConfig structure
+type ScoringAlertsConfig struct {
+	Enabled          bool `json:"enabled"`
+	CloseGameMinutes int  `json:"close_game_minutes"` // alert in the last minutes of the regulation or the overtime
+	CloseGameMargin  int  `json:"close_game_margin"`  // max points difference for a close game
 }
 
 // FootballConfig structure
@@ -115,7 +206,9 @@ func NewConfig() Config {
 	config.WBasketballConfig = createWBasketballConfig()
 	config.VolleyballConfig = createVolleyballConfig()
 	config.NotificationConfig = createNotificationConfig()
-
+	config.SourceHealthConfig = createSourceHealthConfig()
+	config.ReconciliationConfig = createReconciliationConfig()
+	config.StandingsConfig = createStandingsConfig()
 	return config
 }
 
@@ -130,6 +223,11 @@ func (config *Config) GetLivestatsSource(sport string, home bool) []string {
 	return sources
 }
 
+// GetStaleThreshold gives the duration after which a source with unchanged data is marked as stale
+func (config *Config) GetStaleThreshold() time.Duration {
+	return time.Duration(config.SourceHealthConfig.StaleThresholdSeconds) * time.Second
+}
+
 // GetFootballDateCheck gives the xml feed date check flag
 func (config *Config) GetFootballDateCheck() bool {
 	return config.FootballConfig.XMLDateCheck
@@ -265,15 +363,161 @@ func createVolleyballConfig() VolleyballConfig {
 func createNotificationConfig() NotificationConfig {
 	var notificationConfig NotificationConfig
 
+	// the messages are text/template templates with the fields of MessageData, for example {{.HomeTeam}}
 	messages := make(map[string]string)
-	messages["game_title_format"] = "%[1]s vs %[2]s" // [1] - home team name, [2] - away team name
+	messages["game_title_format"] = "{{.HomeTeam}} vs {{.VisitingTeam}}"
 	messages["game_started_msg"] = "The Game has started"
-	messages["game_ended_msg"] = "The Game had ended."
-	messages["game_ended_score_format"] = "Score %[1]s %[2]d : %[3]s %[4]d" // [1] - home team name, [2] - home score, [3] - away team name, [4] - away score
-	messages["news_updates_sport_title_format"] = "Athletics news - %[1]s"  // [1] - news sport / category
+	messages["game_ended_msg"] = "The Game had ended. {{.Score}}"
+	messages["game_ended_win_msg"] = "{{.OurTeam}} wins {{.OurScore}}-{{.OpponentScore}} over {{.Opponent}}!"
+	messages["game_ended_loss_msg"] = "{{.OurTeam}} falls to {{.Opponent}} {{.OpponentScore}}-{{.OurScore}}."
+	messages["game_ended_tie_msg"] = "{{.OurTeam}} and {{.Opponent}} tie {{.OurScore}}-{{.OpponentScore}}."
+	messages["game_ended_score_format"] = "Score {{.HomeTeam}} {{.HomeScore}} : {{.VisitingTeam}} {{.VisitingScore}}"
+	messages["game_corrected_msg"] = "Score correction. {{.Score}}"
+	messages["touchdown_format"] = "Touchdown {{.Team}}! {{.Score}}"
+	messages["field_goal_format"] = "Field goal {{.Team}}! {{.Score}}"
+	messages["lead_change_format"] = "{{.Team}} takes the lead! {{.Score}}"
+	messages["end_of_period_format"] = "End of the {{.Phase}}. {{.Score}}"
+	messages["overtime_started_msg"] = "The Game goes to Over Time. {{.Score}}"
+	messages["period_quarter_format"] = "{{if eq .Period 1}}1st{{else if eq .Period 2}}2nd{{else if eq .Period 3}}3rd{{else}}{{.Period}}th{{end}} Quarter"
+	messages["period_half_format"] = "{{if eq .Period 1}}1st{{else if eq .Period 2}}2nd{{else}}{{.Period}}th{{end}} Half"
+	messages["period_set_format"] = "{{if eq .Period 1}}1st{{else if eq .Period 2}}2nd{{else if eq .Period 3}}3rd{{else}}{{.Period}}th{{end}} Set"
+	messages["period_overtime_label"] = "Over Time"
+	messages["close_game_format"] = "Close game with {{.Remaining}} remaining. {{.Score}}"
+	messages["reminder_msg"] = "The Game starts {{.StartTime}}{{if .Location}} in {{.Location}}{{end}}."
+	messages["schedule_time_changed_msg"] = "New time: {{.StartTime}} (was {{.PreviousStartTime}})."
+	messages["schedule_tbd_resolved_msg"] = "The Game time is set: {{.StartTime}}."
+	messages["schedule_rescheduled_msg"] = "The Game is rescheduled to {{.StartTime}}."
+	messages["schedule_location_changed_msg"] = "New location: {{.Location}} (was {{.PreviousLocation}})."
+	messages["schedule_postponed_msg"] = "The Game is postponed.{{if .Reason}} {{.Reason}}{{end}}"
+	messages["schedule_cancelled_msg"] = "The Game is cancelled.{{if .Reason}} {{.Reason}}{{end}}"
+	messages["recap_msg"] = "Final: {{.OurTeam}} {{.OurScore}}, {{.Opponent}} {{.OpponentScore}}. Read the recap{{if .Title}}: {{.Title}}{{end}}"
+	messages["recap_boxscore_msg"] = "Final: {{.OurTeam}} {{.OurScore}}, {{.Opponent}} {{.OpponentScore}}. The box score is available."
+	messages["news_updates_sport_title_format"] = "Athletics news - {{.Category}}"
 	messages["news_updates_default_title"] = "Athletics news"
-	messages["news_updates_body_content_format"] = "%[1]s" //[1] - news title
+	messages["news_updates_body_content_format"] = "{{.Title}}"
+	messages["news_updates_edited_body_format"] = "Updated: {{.Title}}"
+	messages["news_updates_digest_format"] = "{{.Count}} new stories, including: {{.Title}}"
 	notificationConfig.Messages = messages
 
+	notificationConfig.DefaultLocale = "en"
+	notificationConfig.Locales = []string{"en", "es", "zh"}
+	notificationConfig.PerGameTopics = true
+
+	esMessages := make(map[string]string)
+	esMessages["game_started_msg"] = "El partido ha comenzado"
+	esMessages["game_ended_msg"] = "El partido ha terminado. {{.Score}}"
+	esMessages["game_ended_win_msg"] = "¡{{.OurTeam}} gana {{.OurScore}}-{{.OpponentScore}} a {{.Opponent}}!"
+	esMessages["game_ended_loss_msg"] = "{{.OurTeam}} cae ante {{.Opponent}} {{.OpponentScore}}-{{.OurScore}}."
+	esMessages["game_ended_tie_msg"] = "{{.OurTeam}} y {{.Opponent}} empatan {{.OurScore}}-{{.OpponentScore}}."
+	esMessages["game_ended_score_format"] = "Marcador {{.HomeTeam}} {{.HomeScore}} : {{.VisitingTeam}} {{.VisitingScore}}"
+	esMessages["game_corrected_msg"] = "Corrección del marcador. {{.Score}}"
+	esMessages["touchdown_format"] = "¡Touchdown de {{.Team}}! {{.Score}}"
+	esMessages["field_goal_format"] = "¡Gol de campo de {{.Team}}! {{.Score}}"
+	esMessages["lead_change_format"] = "¡{{.Team}} toma la delantera! {{.Score}}"
+	esMessages["end_of_period_format"] = "Fin del {{.Phase}}. {{.Score}}"
+	esMessages["period_quarter_format"] = "{{.Period}}.º cuarto"
+	esMessages["period_half_format"] = "{{.Period}}.º tiempo"
+	esMessages["period_set_format"] = "{{.Period}}.º set"
+	esMessages["period_overtime_label"] = "tiempo extra"
+	esMessages["overtime_started_msg"] = "El partido se va a tiempo extra. {{.Score}}"
+	esMessages["close_game_format"] = "Partido reñido con {{.Remaining}} restantes. {{.Score}}"
+	esMessages["reminder_msg"] = "El partido comienza {{.StartTime}}{{if .Location}} en {{.Location}}{{end}}."
+	esMessages["schedule_time_changed_msg"] = "Nuevo horario: {{.StartTime}} (antes {{.PreviousStartTime}})."
+	esMessages["schedule_tbd_resolved_msg"] = "Horario del partido confirmado: {{.StartTime}}."
+	esMessages["schedule_rescheduled_msg"] = "El partido se reprogramó para {{.StartTime}}."
+	esMessages["schedule_location_changed_msg"] = "Nueva sede: {{.Location}} (antes {{.PreviousLocation}})."
+	esMessages["schedule_postponed_msg"] = "El partido se pospuso.{{if .Reason}} {{.Reason}}{{end}}"
+	esMessages["schedule_cancelled_msg"] = "El partido se canceló.{{if .Reason}} {{.Reason}}{{end}}"
+	esMessages["recap_msg"] = "Final: {{.OurTeam}} {{.OurScore}}, {{.Opponent}} {{.OpponentScore}}. Lee la crónica{{if .Title}}: {{.Title}}{{end}}"
+	esMessages["recap_boxscore_msg"] = "Final: {{.OurTeam}} {{.OurScore}}, {{.Opponent}} {{.OpponentScore}}. Las estadísticas del partido están disponibles."
+	esMessages["news_updates_sport_title_format"] = "Noticias deportivas - {{.Category}}"
+	esMessages["news_updates_default_title"] = "Noticias deportivas"
+	esMessages["news_updates_edited_body_format"] = "Actualizado: {{.Title}}"
+	esMessages["news_updates_digest_format"] = "{{.Count}} noticias nuevas, entre ellas: {{.Title}}"
+
+	zhMessages := make(map[string]string)
+	zhMessages["game_started_msg"] = "比赛已开始"
+	zhMessages["game_ended_msg"] = "比赛已结束。{{.Score}}"
+	zhMessages["game_ended_win_msg"] = "{{.OurTeam}} 以 {{.OurScore}}-{{.OpponentScore}} 战胜 {{.Opponent}}！"
+	zhMessages["game_ended_loss_msg"] = "{{.OurTeam}} 以 {{.OurScore}}-{{.OpponentScore}} 负于 {{.Opponent}}。"
+	zhMessages["game_ended_tie_msg"] = "{{.OurTeam}} 与 {{.Opponent}} 以 {{.OurScore}}-{{.OpponentScore}} 战平。"
+	zhMessages["game_ended_score_format"] = "比分 {{.HomeTeam}} {{.HomeScore}} : {{.VisitingTeam}} {{.VisitingScore}}"
+	zhMessages["game_corrected_msg"] = "比分更正。{{.Score}}"
+	zhMessages["touchdown_format"] = "{{.Team}} 达阵！{{.Score}}"
+	zhMessages["field_goal_format"] = "{{.Team}} 射门得分！{{.Score}}"
+	zhMessages["lead_change_format"] = "{{.Team}} 取得领先！{{.Score}}"
+	zhMessages["end_of_period_format"] = "{{.Phase}}结束。{{.Score}}"
+	zhMessages["period_quarter_format"] = "第{{.Period}}节"
+	zhMessages["period_half_format"] = "{{if eq .Period 1}}上半场{{else}}下半场{{end}}"
+	zhMessages["period_set_format"] = "第{{.Period}}局"
+	zhMessages["period_overtime_label"] = "加时赛"
+	zhMessages["overtime_started_msg"] = "比赛进入加时赛。{{.Score}}"
+	zhMessages["close_game_format"] = "比赛胶着，还剩 {{.Remaining}}。{{.Score}}"
+	zhMessages["reminder_msg"] = "比赛将于 {{.StartTime}} 开始{{if .Location}}，地点：{{.Location}}{{end}}。"
+	zhMessages["schedule_time_changed_msg"] = "新时间：{{.StartTime}}（原为 {{.PreviousStartTime}}）。"
+	zhMessages["schedule_tbd_resolved_msg"] = "比赛时间已确定：{{.StartTime}}。"
+	zhMessages["schedule_rescheduled_msg"] = "比赛已改期至 {{.StartTime}}。"
+	zhMessages["schedule_location_changed_msg"] = "新地点：{{.Location}}（原为 {{.PreviousLocation}}）。"
+	zhMessages["schedule_postponed_msg"] = "比赛已推迟。{{if .Reason}}{{.Reason}}{{end}}"
+	zhMessages["schedule_cancelled_msg"] = "比赛已取消。{{if .Reason}}{{.Reason}}{{end}}"
+	zhMessages["recap_msg"] = "终场：{{.OurTeam}} {{.OurScore}}，{{.Opponent}} {{.OpponentScore}}。阅读赛后报道{{if .Title}}：{{.Title}}{{end}}"
+	zhMessages["recap_boxscore_msg"] = "终场：{{.OurTeam}} {{.OurScore}}，{{.Opponent}} {{.OpponentScore}}。比赛技术统计已发布。"
+	zhMessages["news_updates_sport_title_format"] = "体育新闻 - {{.Category}}"
+	zhMessages["news_updates_default_title"] = "体育新闻"
+	zhMessages["news_updates_edited_body_format"] = "已更新：{{.Title}}"
+	zhMessages["news_updates_digest_format"] = "{{.Count}} 条新闻，包括：{{.Title}}"
+
+	localizedMessages := make(map[string]map[string]string)
+	localizedMessages["es"] = esMessages
+	localizedMessages["zh"] = zhMessages
+	notificationConfig.LocalizedMessages = localizedMessages
+
+	notificationConfig.ScoringAlerts = ScoringAlertsConfig{Enabled: false, CloseGameMinutes: 2, CloseGameMargin: 3}
+
+	notificationConfig.Reminders = RemindersConfig{Enabled: true, OffsetsMinutes: []int{24 * 60, 60, 15}}
+
+	notificationConfig.Recaps = RecapsConfig{Enabled: true, MaxAgeHours: 24, BoxScoreWaitMinutes: 120}
+
+	notificationConfig.News = NewsConfig{MaxPushesPerCheck: 3, NotifyEdits: false}
+
+	rateLimits := make(map[string]int)
+	rateLimits["default"] = 0
+	rateLimits["lead_change"] = 60
+	quietHours := notifications.QuietHoursConfig{Enabled: false, Start: "22:00", End: "07:00", TimeZone: "America/Chicago"}
+	notificationConfig.Dispatcher = notifications.DispatcherConfig{RateLimits: rateLimits, CoalesceSeconds: 10, QuietHours: quietHours}
+
+	notificationConfig.Outbox = notifications.OutboxConfig{MaxAttempts: 8, InitialBackoffSeconds: 5, MaxBackoffSeconds: 600}
+
+	notificationConfig.Notifier = notifications.NotifierConfig{Transport: notifications.TransportRokwire}
+
 	return notificationConfig
 }
+
+func createSourceHealthConfig() SourceHealthConfig {
+	var sourceHealthConfig SourceHealthConfig
+
+	// timeouts and reviews stop the clock for a while, so keep it long enough to not switch on them
+	sourceHealthConfig.StaleThresholdSeconds = 300
+
+	return sourceHealthConfig
+}
+
+func createReconciliationConfig() ReconciliationConfig {
+	var reconciliationConfig ReconciliationConfig
+
+	reconciliationConfig.Enabled = false //loading all sources for every poll is opt-in
+	reconciliationConfig.Policy = "prefer_priority"
+	reconciliationConfig.RequireAgreementForFinal = false
+
+	return reconciliationConfig
+}
+
+func createStandingsConfig() standings.Config {
+	var standingsConfig standings.Config
+
+	//the file source serves the fixture file for testing
+	standingsConfig.Source = standings.SourceNone
+	standingsConfig.File = "driven/storage/fixtures/standings.json"
+
+	return standingsConfig
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxHealthEvents = 100
+
+// HealthEvent structure
+type HealthEvent struct {
+	GameID  string    `json:"game_id"`
+	Sport   string    `json:"sport"`
+	Source  string    `json:"source"`
+	Type    string    `json:"type"` // stale, recovered, failover, failback, disagreement or agreement
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+type sourceState struct {
+	data        map[string]string
+	lastChanged time.Time
+	stale       bool
+}
+
+// sourceHealth tracks when the data of every game and source has changed for the last time
+type sourceHealth struct {
+	mu     sync.Mutex
+	states map[string]*sourceState
+	active map[string]string
+	events []HealthEvent
+}
+
+func newSourceHealth() *sourceHealth {
+	return &sourceHealth{states: make(map[string]*sourceState), active: make(map[string]string)}
+}
+
+// isStale updates the state for the loaded game and gives if the source is stale. A source is stale when the
+// game is in progress, its clock is not stopped at zero and its data has not changed for longer than the threshold
+func (health *sourceHealth) isStale(item *sidearmModel.LiveGameItem, source string, game model.LiveGame, threshold time.Duration, now time.Time) bool {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	key := item.GameID + "|" + source
+	data := game.Encode()
+	state := health.states[key]
+	if state == nil {
+		health.states[key] = &sourceState{data: data, lastChanged: now}
+		return false
+	}
+	if !reflect.DeepEqual(state.data, data) {
+		state.data = data
+		state.lastChanged = now
+	}
+
+	stale := threshold > 0 && isClockRunning(game) && now.Sub(state.lastChanged) > threshold
+	if stale && !state.stale {
+		msg := fmt.Sprintf("no data changes since %s", state.lastChanged.Format(time.RFC3339))
+		health.emit(HealthEvent{GameID: item.GameID, Sport: item.Sport, Source: source, Type: "stale", Message: msg, Time: now})
+	} else if !stale && state.stale {
+		health.emit(HealthEvent{GameID: item.GameID, Sport: item.Sport, Source: source, Type: "recovered", Message: "the data is updating again", Time: now})
+	}
+	state.stale = stale
+	return stale
+}
+
+// setActive stores the source used for the game and emits failover or failback event when it changes
+func (health *sourceHealth) setActive(item *sidearmModel.LiveGameItem, source string, sources []string, now time.Time) {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	previous, exists := health.active[item.GameID]
+	health.active[item.GameID] = source
+	if !exists || previous == source {
+		return
+	}
+
+	eventType := "failover"
+	if indexOf(sources, source) < indexOf(sources, previous) {
+		eventType = "failback"
+	}
+	msg := fmt.Sprintf("switched from %s to %s", previous, source)
+	health.emit(HealthEvent{GameID: item.GameID, Sport: item.Sport, Source: source, Type: eventType, Message: msg, Time: now})
+}
+
+// lastChanged gives the time of the last data change for the game and source
+func (health *sourceHealth) lastChanged(item *sidearmModel.LiveGameItem, source string) time.Time {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	state := health.states[item.GameID+"|"+source]
+	if state == nil {
+		return time.Time{}
+	}
+	return state.lastChanged
+}
+
+// forget drops the states of the game for all the sources
+func (health *sourceHealth) forget(gameID string) {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	for key := range health.states {
+		if strings.HasPrefix(key, gameID+"|") {
+			delete(health.states, key)
+		}
+	}
+	delete(health.active, gameID)
+}
+
+// prune drops the states of the games which are not live
+func (health *sourceHealth) prune(live map[string]bool) {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	for key := range health.states {
+		if gameID := strings.SplitN(key, "|", 2)[0]; !live[gameID] {
+			delete(health.states, key)
+		}
+	}
+	for gameID := range health.active {
+		if !live[gameID] {
+			delete(health.active, gameID)
+		}
+	}
+}
+
+func (health *sourceHealth) addEvent(event HealthEvent) {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	health.emit(event)
+}
+
+func (health *sourceHealth) healthEvents() []HealthEvent {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	events := make([]HealthEvent, len(health.events))
+	copy(events, health.events)
+	return events
+}
+
+func (health *sourceHealth) emit(event HealthEvent) {
+	log.Printf("source: health event -> type:%s sport:%s gameId:%s source:%s %s", event.Type, event.Sport, event.GameID, event.Source, event.Message)
+	health.events = append(health.events, event)
+	if len(health.events) > maxHealthEvents {
+		health.events = health.events[len(health.events)-maxHealthEvents:]
+	}
+}
+
+// isClockRunning checks if the game is in progress and the clock is not stopped at the end of a period
+func isClockRunning(game model.LiveGame) bool {
+	return game.GetHasStarted() && !game.GetIsComplete() && game.GetClockSeconds() > 0
+}
+
+func indexOf(values []string, value string) int {
+	for index, current := range values {
+		if current == value {
+			return index
+		}
+	}
+	return -1
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testGame is a live game with the values set by the test
+type testGame struct {
+	id            int
+	started       bool
+	complete      bool
+	clock         int
+	period        int
+	homeScore     int
+	visitingScore int
+}
+
+func (game *testGame) GetType() string       { return "football" }
+func (game *testGame) GetGameID() int        { return game.id }
+func (game *testGame) GetPath() string       { return "football" }
+func (game *testGame) GetHasStarted() bool   { return game.started }
+func (game *testGame) GetIsComplete() bool   { return game.complete }
+func (game *testGame) GetClockSeconds() int  { return game.clock }
+func (game *testGame) GetPeriod() int        { return game.period }
+func (game *testGame) GetHomeScore() int     { return game.homeScore }
+func (game *testGame) GetVisitingScore() int { return game.visitingScore }
+func (game *testGame) GetCustomData() string { return "" }
+
+func (game *testGame) Encode() map[string]string {
+	return map[string]string{"GameId": strconv.Itoa(game.id), "HasStarted": strconv.FormatBool(game.started),
+		"IsComplete": strconv.FormatBool(game.complete), "ClockSeconds": strconv.Itoa(game.clock), "Period": strconv.Itoa(game.period),
+		"HomeScore": strconv.Itoa(game.homeScore), "VisitingScore": strconv.Itoa(game.visitingScore)}
+}
+
+func TestSourceHealthIsStale(t *testing.T) {
+	item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football"}
+	start := time.Date(2023, 10, 21, 18, 0, 0, 0, time.UTC)
+	threshold := 2 * time.Minute
+	running := &testGame{id: 1001, started: true, clock: 600, period: 1}
+	scored := &testGame{id: 1001, started: true, clock: 540, period: 1, homeScore: 7}
+	stopped := &testGame{id: 1001, started: true, clock: 0, period: 1}
+	final := &testGame{id: 1001, started: true, complete: true, clock: 600, period: 4}
+
+	type step struct {
+		game  *testGame
+		after time.Duration
+		stale bool
+		event string
+	}
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		steps     []step
+	}{
+		{"the first data is fresh", threshold, []step{{running, 0, false, ""}}},
+		{"changing data is fresh", threshold, []step{{running, 0, false, ""}, {scored, 3 * time.Minute, false, ""}}},
+		{"unchanged data within the threshold is fresh", threshold, []step{{running, 0, false, ""}, {running, time.Minute, false, ""}}},
+		{"unchanged data with running clock is stale", threshold, []step{{running, 0, false, ""}, {running, 3 * time.Minute, true, "stale"}}},
+		{"the stale event is emitted once", threshold, []step{{running, 0, false, ""}, {running, 3 * time.Minute, true, "stale"}, {running, 4 * time.Minute, true, ""}}},
+		{"changed data recovers", threshold, []step{{running, 0, false, ""}, {running, 3 * time.Minute, true, "stale"}, {scored, 4 * time.Minute, false, "recovered"}}},
+		{"clock stopped at zero is not stale", threshold, []step{{stopped, 0, false, ""}, {stopped, 10 * time.Minute, false, ""}}},
+		{"complete game is not stale", threshold, []step{{final, 0, false, ""}, {final, 10 * time.Minute, false, ""}}},
+		{"zero threshold disables the check", 0, []step{{running, 0, false, ""}, {running, 10 * time.Minute, false, ""}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			health := newSourceHealth()
+			for i, step := range test.steps {
+				events := len(health.healthEvents())
+				stale := health.isStale(item, "xml_feed", step.game, test.threshold, start.Add(step.after))
+				if stale != step.stale {
+					t.Errorf("step %d: stale %t, expected %t", i, stale, step.stale)
+				}
+				newEvents := health.healthEvents()[events:]
+				if len(step.event) == 0 && len(newEvents) > 0 {
+					t.Errorf("step %d: unexpected events %+v", i, newEvents)
+				}
+				if len(step.event) > 0 && (len(newEvents) != 1 || newEvents[0].Type != step.event || newEvents[0].Source != "xml_feed") {
+					t.Errorf("step %d: events %+v, expected %s", i, newEvents, step.event)
+				}
+			}
+		})
+	}
+}
+
+func TestSourceHealthSetActive(t *testing.T) {
+	item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football"}
+	sources := []string{"xml_feed", "sidearm"}
+	now := time.Date(2023, 10, 21, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		active []string
+		events []string
+	}{
+		{"the first source is not a switch", []string{"xml_feed"}, nil},
+		{"the same source is not a switch", []string{"xml_feed", "xml_feed"}, nil},
+		{"lower priority source is a failover", []string{"xml_feed", "sidearm"}, []string{"failover"}},
+		{"higher priority source is a failback", []string{"xml_feed", "sidearm", "xml_feed"}, []string{"failover", "failback"}},
+		{"starting on the fallback source", []string{"sidearm", "xml_feed"}, []string{"failback"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			health := newSourceHealth()
+			for _, source := range test.active {
+				health.setActive(item, source, sources, now)
+			}
+			events := health.healthEvents()
+			if len(events) != len(test.events) {
+				t.Fatalf("events %+v, expected %v", events, test.events)
+			}
+			for i, event := range events {
+				if event.Type != test.events[i] {
+					t.Errorf("event %d is %s, expected %s", i, event.Type, test.events[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSourceHealthForgetAndPrune(t *testing.T) {
+	now := time.Date(2023, 10, 21, 18, 0, 0, 0, time.UTC)
+	first := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football"}
+	second := &sidearmModel.LiveGameItem{GameID: "1002", Sport: "mbball"}
+	sources := []string{"xml_feed", "sidearm"}
+
+	health := newSourceHealth()
+	for _, item := range []*sidearmModel.LiveGameItem{first, second} {
+		for _, source := range sources {
+			health.isStale(item, source, &testGame{started: true, clock: 600}, time.Minute, now)
+		}
+		health.setActive(item, "xml_feed", sources, now)
+	}
+
+	health.forget(first.GameID)
+	if !health.lastChanged(first, "xml_feed").IsZero() || !health.lastChanged(first, "sidearm").IsZero() {
+		t.Error("the forgotten game still has states")
+	}
+	if _, exists := health.active[first.GameID]; exists {
+		t.Error("the forgotten game still has an active source")
+	}
+	if health.lastChanged(second, "xml_feed").IsZero() {
+		t.Error("the other game lost its state")
+	}
+
+	health.prune(map[string]bool{})
+	if len(health.states) != 0 || len(health.active) != 0 {
+		t.Errorf("the states of the not live games are kept %v %v", health.states, health.active)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// ScoreMessageKey is the message which renders the score of a game. It is available as {{.Score}} in the other messages.
+const ScoreMessageKey string = "game_ended_score_format"
+
+// the messages which render the label of a period, it is available as {{.Phase}} in the other messages
+const (
+	periodQuarterKey  string = "period_quarter_format"
+	periodHalfKey     string = "period_half_format"
+	periodSetKey      string = "period_set_format"
+	periodOvertimeKey string = "period_overtime_label"
+)
+
+// fmtVerbRegex matches the fmt verbs which were used by the messages before the templates
+var fmtVerbRegex = regexp.MustCompile(`%(\[(\d+)\])?[sdv]`)
+
+// legacyMessageFields are the template fields for the fmt verb arguments of the messages before the templates
+var legacyMessageFields = map[string][]string{
+	"game_title_format":                {"HomeTeam", "VisitingTeam"},
+	"game_ended_score_format":          {"HomeTeam", "HomeScore", "VisitingTeam", "VisitingScore"},
+	"touchdown_format":                 {"Team", "Score"},
+	"field_goal_format":                {"Team", "Score"},
+	"lead_change_format":               {"Team", "Score"},
+	"end_of_period_format":             {"Phase", "Score"},
+	"close_game_format":                {"Remaining", "Score"},
+	"news_updates_sport_title_format":  {"Category"},
+	"news_updates_body_content_format": {"Title"},
+}
+
+// legacyScoreMessages were followed by the score before the templates
+var legacyScoreMessages = []string{"game_ended_msg", "game_corrected_msg", "overtime_started_msg"}
+
+// MessageData contains the fields which could be used in the message templates, for example {{.HomeTeam}}
+type MessageData struct {
+	Sport             string
+	HomeTeam          string
+	VisitingTeam      string
+	Opponent          string
+	OurTeam           string
+	HomeScore         int
+	VisitingScore     int
+	OurScore          int
+	OpponentScore     int
+	Result            string // win, loss or tie for our team
+	Score             string // the rendered score message
+	Team              string // the team of the scoring play or the lead change
+	Phase             string // the label of the current or the ended period rendered in the locale, for example "2nd Quarter"
+	Period            int    // the number of the current or the ended period
+	Overtime          bool   // the period is after the regular periods
+	Remaining         string // the remaining time, for example "1:45"
+	StartTime         string // the start of the game in the Chicago time zone, for example "Sat, Oct 21 2:30 PM"
+	Location          string
+	PreviousStartTime string // the start of the game before the schedule change
+	PreviousLocation  string // the location of the game before the schedule change
+	Reason            string // the reason of the postponement or the cancellation
+	Category          string // the news category
+	Title             string // the news title
+	Count             int    // the number of the stories in the news digest
+}
+
+// SampleMessageData gives data for validating and previewing the templates
+func SampleMessageData() MessageData {
+	return MessageData{Sport: "football", HomeTeam: "Illinois", VisitingTeam: "Iowa", Opponent: "Iowa", OurTeam: "Illinois",
+		HomeScore: 21, VisitingScore: 17, OurScore: 21, OpponentScore: 17, Result: "win", Team: "Illinois", Period: 3, Remaining: "1:45",
+		StartTime: "Sat, Oct 21 2:30 PM", Location: "Champaign, Ill.",
+		PreviousStartTime: "Sat, Oct 21 11:00 AM", PreviousLocation: "Chicago, Ill.", Reason: "Weather", Category: "Football",
+		Title: "Illini win the homecoming game", Count: 5}
+}
+
+// RenderPeriodLabel renders the label of the period of the data in the locale of the messages, for example "2nd Quarter"
+func RenderPeriodLabel(messages map[string]string, data MessageData) string {
+	if data.Period <= 0 {
+		return ""
+	}
+	key := periodQuarterKey
+	if data.Overtime {
+		key = periodOvertimeKey
+	} else if data.Sport == "mbball" {
+		key = periodHalfKey
+	} else if data.Sport == "wvball" {
+		key = periodSetKey
+	}
+	result, err := RenderTemplate(messages[key], data)
+	if err != nil {
+		log.Printf("messages -> RenderPeriodLabel: failed to render message %s. Reason: %s", key, err.Error())
+		return ""
+	}
+	return result
+}
+
+// RenderTemplate renders the template text with the data
+func RenderTemplate(text string, data MessageData) (string, error) {
+	if fmtVerbRegex.MatchString(text) {
+		return "", fmt.Errorf("fmt verbs are not supported, use the named fields like {{.HomeTeam}} instead")
+	}
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var result strings.Builder
+	err = tmpl.Execute(&result, data)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// RenderMessage renders the message with the key from the messages of a locale. The score and the period label are rendered first,
+// so they could be used as {{.Score}} and {{.Phase}}.
+func RenderMessage(messages map[string]string, key string, data MessageData) string {
+	if key != ScoreMessageKey && len(data.Score) == 0 && strings.Contains(messages[key], ".Score") {
+		data.Score = RenderMessage(messages, ScoreMessageKey, data)
+	}
+	if len(data.Phase) == 0 && strings.Contains(messages[key], ".Phase") {
+		data.Phase = RenderPeriodLabel(messages, data)
+	}
+	result, err := RenderTemplate(messages[key], data)
+	if err != nil {
+		log.Printf("messages -> RenderMessage: failed to render message %s. Reason: %s", key, err.Error())
+		return ""
+	}
+	return result
+}
+
+// TranslateLegacyMessages replaces the fmt verbs of the messages stored before the templates with the template fields,
+// so the old configs are still valid
+func (c NotificationConfig) TranslateLegacyMessages() {
+	translateLegacyMessages(c.DefaultLocale, c.Messages)
+	for locale, messages := range c.LocalizedMessages {
+		translateLegacyMessages(locale, messages)
+	}
+}
+
+func translateLegacyMessages(locale string, messages map[string]string) {
+	translated := 0
+	for key, message := range messages {
+		legacy := translateLegacyMessage(key, message)
+		if legacy != message {
+			messages[key] = legacy
+			translated++
+		}
+	}
+	if translated > 0 {
+		log.Printf("messages -> translateLegacyMessages: translated %d %s messages to template fields", translated, locale)
+	}
+}
+
+// translateLegacyMessage replaces the fmt verbs of the message with the template fields. The messages which were followed by the score
+// get the score if they do not use any template field, so a legacy message is translated even if the other messages were edited since.
+func translateLegacyMessage(key string, message string) string {
+	fields := legacyMessageFields[key]
+	next := 0
+	message = fmtVerbRegex.ReplaceAllStringFunc(message, func(verb string) string {
+		index := next
+		if match := fmtVerbRegex.FindStringSubmatch(verb); len(match[2]) > 0 {
+			index, _ = strconv.Atoi(match[2])
+			index--
+		}
+		next = index + 1
+		if index < 0 || index >= len(fields) {
+			//the validation reports the unknown arguments
+			return verb
+		}
+		return "{{." + fields[index] + "}}"
+	})
+	if indexOf(legacyScoreMessages, key) != -1 && len(message) > 0 && !strings.Contains(message, "{{") {
+		message = strings.TrimSpace(message + " {{.Score}}")
+	}
+	return message
+}
+
+// Validate checks that all message templates in the default and the localized messages could be rendered and the outbox config is valid
+func (c NotificationConfig) Validate() error {
+	if len(c.DefaultLocale) == 0 {
+		return fmt.Errorf("missing default locale")
+	}
+	err := validateMessages(c.DefaultLocale, c.Messages)
+	if err != nil {
+		return err
+	}
+	for locale, messages := range c.LocalizedMessages {
+		err = validateMessages(locale, messages)
+		if err != nil {
+			return err
+		}
+	}
+	return c.Outbox.Validate()
+}
+
+func validateMessages(locale string, messages map[string]string) error {
+	keys := make([]string, 0, len(messages))
+	for key := range messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	data := SampleMessageData()
+	for _, key := range keys {
+		_, err := RenderTemplate(messages[key], data)
+		if err != nil {
+			return fmt.Errorf("invalid message %s for locale %s: %s", key, locale, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRenderPeriodLabel(t *testing.T) {
+	config := NewConfig().NotificationConfig
+	tests := []struct {
+		locale   string
+		sport    string
+		period   int
+		overtime bool
+		expected string
+	}{
+		{"en", "football", 1, false, "1st Quarter"},
+		{"en", "football", 2, false, "2nd Quarter"},
+		{"en", "football", 3, false, "3rd Quarter"},
+		{"en", "wbball", 4, false, "4th Quarter"},
+		{"en", "football", 5, true, "Over Time"},
+		{"en", "mbball", 2, false, "2nd Half"},
+		{"en", "wvball", 5, false, "5th Set"},
+		{"en", "football", 0, false, ""},
+		{"es", "football", 2, false, "2.º cuarto"},
+		{"es", "mbball", 1, false, "1.º tiempo"},
+		{"es", "wvball", 3, false, "3.º set"},
+		{"es", "mbball", 3, true, "tiempo extra"},
+		{"zh", "football", 3, false, "第3节"},
+		{"zh", "mbball", 1, false, "上半场"},
+		{"zh", "mbball", 2, false, "下半场"},
+		{"zh", "wvball", 4, false, "第4局"},
+		{"zh", "football", 5, true, "加时赛"},
+	}
+	for _, test := range tests {
+		t.Run(test.locale+" "+test.expected, func(t *testing.T) {
+			data := MessageData{Sport: test.sport, Period: test.period, Overtime: test.overtime}
+			if label := RenderPeriodLabel(config.GetMessages(test.locale), data); label != test.expected {
+				t.Errorf("label %s, expected %s", label, test.expected)
+			}
+		})
+	}
+}
+
+func TestRenderEndOfPeriod(t *testing.T) {
+	config := NewConfig().NotificationConfig
+	data := MessageData{Sport: "football", HomeTeam: "Illinois", VisitingTeam: "Iowa", HomeScore: 14, VisitingScore: 7, Period: 2}
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{"en", "End of the 2nd Quarter. Score Illinois 14 : Iowa 7"},
+		{"es", "Fin del 2.º cuarto. Marcador Illinois 14 : Iowa 7"},
+		{"zh", "第2节结束。比分 Illinois 14 : Iowa 7"},
+	}
+	for _, test := range tests {
+		t.Run(test.locale, func(t *testing.T) {
+			if message := RenderMessage(config.GetMessages(test.locale), "end_of_period_format", data); message != test.expected {
+				t.Errorf("message %s, expected %s", message, test.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultMessagesAreLocalized(t *testing.T) {
+	config := NewConfig().NotificationConfig
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	//the messages of the live games are translated in every default locale
+	keys := []string{"game_started_msg", "game_ended_msg", "game_ended_score_format", "touchdown_format", "field_goal_format", "lead_change_format",
+		"end_of_period_format", "overtime_started_msg", "close_game_format", periodQuarterKey, periodHalfKey, periodSetKey, periodOvertimeKey}
+	for locale, messages := range config.LocalizedMessages {
+		for _, key := range keys {
+			if len(messages[key]) == 0 {
+				t.Errorf("the message %s is missing for the locale %s", key, locale)
+			}
+		}
+	}
+}
+
+// legacyConfig is a notification config stored before the templates, the localized messages were added later without fmt verbs
+const legacyConfig = `{
+	"default_locale": "en",
+	"locales": ["en", "es"],
+	"messages": {
+		"game_title_format": "%s vs %s",
+		"game_ended_msg": "The Game had ended.",
+		"game_ended_score_format": "Score %s %d : %s %d",
+		"game_corrected_msg": "Score correction. {{.Score}}",
+		"touchdown_format": "Touchdown %s! %s",
+		"end_of_period_format": "End of the %[1]s. %[2]s"
+	},
+	"localized_messages": {
+		"es": {
+			"game_ended_msg": "El partido ha terminado.",
+			"game_started_msg": "El partido ha comenzado",
+			"touchdown_format": "¡Touchdown de {{.Team}}! {{.Score}}"
+		}
+	},
+	"outbox": {"max_attempts": 5, "initial_backoff_seconds": 2, "max_backoff_seconds": 300}
+}`
+
+func TestTranslateLegacyMessages(t *testing.T) {
+	var config NotificationConfig
+	if err := json.Unmarshal([]byte(legacyConfig), &config); err != nil {
+		t.Fatal(err)
+	}
+	config.TranslateLegacyMessages()
+	if err := config.Validate(); err != nil {
+		t.Fatalf("the translated config is invalid: %s", err)
+	}
+
+	tests := []struct {
+		locale   string
+		key      string
+		expected string
+	}{
+		{"en", "game_title_format", "{{.HomeTeam}} vs {{.VisitingTeam}}"},
+		{"en", "game_ended_msg", "The Game had ended. {{.Score}}"},
+		{"en", "game_ended_score_format", "Score {{.HomeTeam}} {{.HomeScore}} : {{.VisitingTeam}} {{.VisitingScore}}"},
+		{"en", "game_corrected_msg", "Score correction. {{.Score}}"},
+		{"en", "touchdown_format", "Touchdown {{.Team}}! {{.Score}}"},
+		{"en", "end_of_period_format", "End of the {{.Phase}}. {{.Score}}"},
+		{"es", "game_ended_msg", "El partido ha terminado. {{.Score}}"},
+		{"es", "game_started_msg", "El partido ha comenzado"},
+		{"es", "touchdown_format", "¡Touchdown de {{.Team}}! {{.Score}}"},
+	}
+	for _, test := range tests {
+		t.Run(test.locale+" "+test.key, func(t *testing.T) {
+			messages := config.Messages
+			if test.locale != config.DefaultLocale {
+				messages = config.LocalizedMessages[test.locale]
+			}
+			if message := messages[test.key]; message != test.expected {
+				t.Errorf("message %s, expected %s", message, test.expected)
+			}
+		})
+	}
+
+	data := MessageData{HomeTeam: "Illinois", VisitingTeam: "Iowa", HomeScore: 21, VisitingScore: 14}
+	if message := RenderMessage(config.GetMessages("es"), "game_ended_msg", data); message != "El partido ha terminado. Score Illinois 21 : Iowa 14" {
+		t.Errorf("the translated message renders %s", message)
+	}
+}
+
+func TestTranslateLegacyMessagesKeepsTheTemplates(t *testing.T) {
+	config := NewConfig().NotificationConfig
+	expected := NewConfig().NotificationConfig
+	config.TranslateLegacyMessages()
+	for key, message := range config.Messages {
+		if message != expected.Messages[key] {
+			t.Errorf("the template %s is changed to %s", key, message)
+		}
+	}
+	for locale, messages := range config.LocalizedMessages {
+		for key, message := range messages {
+			if message != expected.LocalizedMessages[locale][key] {
+				t.Errorf("the %s template %s is changed to %s", locale, key, message)
+			}
+		}
+	}
+}
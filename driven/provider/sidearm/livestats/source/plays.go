@@ -0,0 +1,186 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how many games keep their play by play
+const maxPlayByPlays = 50
+
+// feedPlay is a play from the xml with the key which identifies it between the loads
+type feedPlay struct {
+	key  string
+	play model.Play
+}
+
+type gamePlays struct {
+	Plays model.GamePlays `json:"plays"`
+	Seqs  map[string]int  `json:"seqs"` // the sequence numbers by the play keys
+}
+
+// playByPlay keeps the plays of the latest games, the final plays are persisted with their sequence numbers so they
+// are kept after they are removed from the memory and after restart
+type playByPlay struct {
+	mu      sync.Mutex
+	storage Storage
+	games   map[int]*gamePlays
+}
+
+func newPlayByPlay(storage Storage) *playByPlay {
+	return &playByPlay{storage: storage, games: make(map[int]*gamePlays)}
+}
+
+// set replaces the plays of the game with the plays from the xml in their order. The plays which were loaded before keep
+// their sequence numbers and the new ones get the next numbers.
+func (pbp *playByPlay) set(item *sidearmModel.LiveGameItem, gameID int, plays []feedPlay, isComplete bool) {
+	pbp.mu.Lock()
+	game := pbp.games[gameID]
+	if game == nil {
+		//continue the numbering of the saved plays
+		game = pbp.load(gameID)
+		if game == nil {
+			game = &gamePlays{Seqs: make(map[string]int)}
+		}
+		pbp.games[gameID] = game
+	}
+	previous := game.Plays
+
+	//the same plays are numbered by their order
+	counts := make(map[string]int)
+	result := make([]model.Play, len(plays))
+	for i, feedPlay := range plays {
+		counts[feedPlay.key]++
+		key := fmt.Sprintf("%s#%d", feedPlay.key, counts[feedPlay.key])
+		seq, exists := game.Seqs[key]
+		if !exists {
+			game.Plays.LastSeq++
+			seq = game.Plays.LastSeq
+			game.Seqs[key] = seq
+		}
+		result[i] = feedPlay.play
+		result[i].Seq = seq
+	}
+	game.Plays.GameID = item.GameID
+	game.Plays.Sport = item.Sport
+	game.Plays.IsComplete = isComplete
+	game.Plays.UpdatedAt = time.Now()
+	game.Plays.Plays = result
+
+	var final *gamePlays
+	if isComplete && (!previous.IsComplete || !reflect.DeepEqual(previous.Plays, result)) {
+		final = &gamePlays{Plays: game.Plays, Seqs: make(map[string]int, len(game.Seqs))}
+		for key, seq := range game.Seqs {
+			final.Seqs[key] = seq
+		}
+	}
+	if len(pbp.games) > maxPlayByPlays {
+		pbp.removeOldest()
+	}
+	pbp.mu.Unlock()
+
+	if final != nil {
+		err := pbp.storage.SaveState(playsStateName(gameID), final)
+		if err != nil {
+			log.Printf("source: playByPlay -> failed to save the final plays of game %d. Reason: %s", gameID, err.Error())
+		}
+	}
+}
+
+// load gives the saved plays of the game, it gives nil if the plays of the game have not been saved
+func (pbp *playByPlay) load(gameID int) *gamePlays {
+	var saved gamePlays
+	err := pbp.storage.LoadState(playsStateName(gameID), &saved)
+	if err != nil || saved.Seqs == nil {
+		return nil
+	}
+	return &saved
+}
+
+func (pbp *playByPlay) removeOldest() {
+	var oldestID int
+	var oldest *gamePlays
+	for id, game := range pbp.games {
+		if oldest == nil || game.Plays.UpdatedAt.Before(oldest.Plays.UpdatedAt) {
+			oldestID = id
+			oldest = game
+		}
+	}
+	delete(pbp.games, oldestID)
+}
+
+// get gives the plays of the game with higher sequence numbers than since, it gives nil if there are no plays for the game
+func (pbp *playByPlay) get(gameID int, since int) *model.GamePlays {
+	pbp.mu.Lock()
+	var plays model.GamePlays
+	game := pbp.games[gameID]
+	if game != nil {
+		plays = game.Plays
+	}
+	pbp.mu.Unlock()
+	if game == nil {
+		//the final plays of a game which is not in the memory
+		game = pbp.load(gameID)
+		if game == nil {
+			return nil
+		}
+		plays = game.Plays
+	}
+
+	result := plays
+	result.Plays = []model.Play{}
+	for _, play := range plays.Plays {
+		if play.Seq > since {
+			result.Plays = append(result.Plays, play)
+		}
+	}
+	return &result
+}
+
+func playsStateName(gameID int) string {
+	return fmt.Sprintf("plays_%d", gameID)
+}
+
+// getPlayTeam gives if the play is for the home team from the vh value, nil if it is for nobody
+func getPlayTeam(vh string) *bool {
+	var home bool
+	switch strings.ToUpper(vh) {
+	case "H":
+		home = true
+	case "V":
+		home = false
+	default:
+		return nil
+	}
+	return &home
+}
+
+// getPlayScore gives the score from the xml attribute, nil if there is no score
+func getPlayScore(score string) *int {
+	value, err := strconv.Atoi(strings.TrimSpace(score))
+	if err != nil {
+		return nil
+	}
+	return &value
+}
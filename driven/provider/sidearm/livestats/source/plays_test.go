@@ -0,0 +1,198 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"sport/core/model"
+	"testing"
+)
+
+func findPlay(plays *model.GamePlays, text string) *model.Play {
+	for i := range plays.Plays {
+		if plays.Plays[i].Text == text {
+			return &plays.Plays[i]
+		}
+	}
+	return nil
+}
+
+func TestFootballPlays(t *testing.T) {
+	storage := newMemoryStorage()
+	item := replayItem("football")
+	source := newXMLFootballSource(replayConfig(), "", "", "", newBoxScores(storage), newPlayByPlay(storage))
+	load := func(xmlData []byte) (model.LiveGame, error) {
+		return source.loadXML(item, xmlData)
+	}
+
+	replay(t, load, "football_1.xml")
+	first := source.plays.get(1001, 0)
+	if first == nil || len(first.Plays) != 6 || first.LastSeq != 6 {
+		t.Fatalf("unexpected plays after the first load %+v", first)
+	}
+	//the interception is returned by the home team while the visiting team has the ball
+	pickSix := findPlay(first, "Doe, John pass intercepted by Green, Tim at the V45, Green, Tim return 45 yards to the V00, TOUCHDOWN, clock 10:12.")
+	if pickSix == nil || !pickSix.Scoring || pickSix.Home == nil || !*pickSix.Home {
+		t.Errorf("the interception return is not attributed to the home team %+v", pickSix)
+	}
+	rush := findPlay(first, "Roe, Sam rush for 3 yards to the V28 (Green, Tim).")
+	if rush == nil || rush.Scoring || rush.Home == nil || *rush.Home {
+		t.Errorf("unexpected visiting rush %+v", rush)
+	}
+
+	replay(t, load, "football_2.xml")
+	second := source.plays.get(1001, 0)
+	if second == nil || len(second.Plays) != 9 || second.LastSeq != 9 || !second.IsComplete {
+		t.Fatalf("unexpected plays after the second load %+v", second)
+	}
+	//the plays from the first load keep their sequence numbers
+	for i, play := range first.Plays {
+		if second.Plays[i].Seq != play.Seq {
+			t.Errorf("play %q has sequence %d, expected %d", play.Text, second.Plays[i].Seq, play.Seq)
+		}
+	}
+	//the stat crew has edited the text of the play without a play id
+	edited := findPlay(second, "Roe, Sam rush for 4 yards to the V29 (Green, Tim; Hill, Joe).")
+	if edited == nil || edited.Seq != rush.Seq {
+		t.Errorf("the edited play got a new sequence %+v, expected %d", edited, rush.Seq)
+	}
+	fieldGoal := findPlay(second, "Kerr, Drew field goal attempt from 38 GOOD.")
+	if fieldGoal == nil || !fieldGoal.Scoring || fieldGoal.Home == nil || *fieldGoal.Home {
+		t.Errorf("the field goal is not attributed to the visiting team %+v", fieldGoal)
+	}
+	if since := source.plays.get(1001, first.LastSeq); since == nil || len(since.Plays) != 3 {
+		t.Errorf("unexpected new plays %+v", since)
+	}
+
+	//the final plays are kept after restart
+	restarted := newXMLFootballSource(replayConfig(), "", "", "", newBoxScores(storage), newPlayByPlay(storage))
+	if saved := restarted.plays.get(1001, 0); saved == nil || len(saved.Plays) != 9 || saved.LastSeq != 9 {
+		t.Errorf("unexpected saved plays %+v", saved)
+	}
+}
+
+func TestBasketballPlays(t *testing.T) {
+	storage := newMemoryStorage()
+	item := replayItem("mbball")
+	source := newXMLBasketballSource(replayConfig(), "", "", "", newBoxScores(storage), newPlayByPlay(storage))
+	load := func(xmlData []byte) (model.LiveGame, error) {
+		return source.loadXML(item, xmlData)
+	}
+
+	replay(t, load, "basketball_1.xml")
+	first := source.plays.get(1001, 0)
+	if first == nil || len(first.Plays) != 10 || first.LastSeq != 10 {
+		t.Fatalf("unexpected plays after the first load %+v", first)
+	}
+	//the two free throws at the same time are different plays
+	var freeThrows []model.Play
+	for _, play := range first.Plays {
+		if play.Type == "FT" {
+			freeThrows = append(freeThrows, play)
+		}
+	}
+	if len(freeThrows) != 2 || freeThrows[0].Seq == freeThrows[1].Seq || *freeThrows[1].HomeScore != 40 {
+		t.Errorf("unexpected free throws %+v", freeThrows)
+	}
+	threePointer := first.Plays[3]
+	if threePointer.Text != "ILL team, Domask, Justin, action - GOOD, type - 3PTR" || !threePointer.Scoring ||
+		threePointer.Home == nil || !*threePointer.Home || threePointer.Player != "Domask, Justin" {
+		t.Errorf("unexpected three pointer %+v", threePointer)
+	}
+	rebound := first.Plays[2]
+	if rebound.Player != "" || rebound.Scoring {
+		t.Errorf("unexpected team rebound %+v", rebound)
+	}
+
+	replay(t, load, "basketball_2.xml")
+	second := source.plays.get(1001, 0)
+	if second == nil || len(second.Plays) != 13 || second.LastSeq != 13 || !second.IsComplete {
+		t.Fatalf("unexpected plays after the second load %+v", second)
+	}
+	for i, play := range first.Plays {
+		if second.Plays[i].Seq != play.Seq {
+			t.Errorf("play %q has sequence %d, expected %d", play.Text, second.Plays[i].Seq, play.Seq)
+		}
+	}
+	if since := source.plays.get(1001, first.LastSeq); since == nil || len(since.Plays) != 3 {
+		t.Errorf("unexpected new plays %+v", since)
+	}
+
+	//the final plays are kept after restart
+	restarted := newXMLBasketballSource(replayConfig(), "", "", "", newBoxScores(storage), newPlayByPlay(storage))
+	if saved := restarted.plays.get(1001, 0); saved == nil || len(saved.Plays) != 13 || saved.LastSeq != 13 {
+		t.Errorf("unexpected saved plays %+v", saved)
+	}
+}
+
+func TestPlayByPlaySequences(t *testing.T) {
+	tests := []struct {
+		name     string
+		loads    [][]string // the keys of the plays in every load
+		expected []int      // the sequence numbers after the last load
+	}{
+		{"new plays get the next numbers", [][]string{{"a", "b"}, {"a", "b", "c"}}, []int{1, 2, 3}},
+		{"the same plays are numbered by their order", [][]string{{"a", "a"}, {"a", "a", "a"}}, []int{1, 2, 3}},
+		{"a play inserted before keeps the numbers", [][]string{{"a", "c"}, {"a", "b", "c"}}, []int{1, 3, 2}},
+		{"a removed play does not free its number", [][]string{{"a", "b"}, {"a"}, {"a", "c"}}, []int{1, 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pbp := newPlayByPlay(newMemoryStorage())
+			item := replayItem("football")
+			for _, keys := range test.loads {
+				plays := make([]feedPlay, len(keys))
+				for i, key := range keys {
+					plays[i] = feedPlay{key: key, play: model.Play{Text: key}}
+				}
+				pbp.set(item, 1001, plays, false)
+			}
+
+			plays := pbp.get(1001, 0)
+			if len(plays.Plays) != len(test.expected) {
+				t.Fatalf("plays %+v, expected %d", plays.Plays, len(test.expected))
+			}
+			for i, play := range plays.Plays {
+				if play.Seq != test.expected[i] {
+					t.Errorf("play %d %s has sequence %d, expected %d", i, play.Text, play.Seq, test.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPlayByPlaySince(t *testing.T) {
+	pbp := newPlayByPlay(newMemoryStorage())
+	item := replayItem("football")
+	pbp.set(item, 1001, []feedPlay{{key: "a"}, {key: "b"}, {key: "c"}}, false)
+
+	tests := []struct {
+		since    int
+		expected int
+	}{
+		{0, 3},
+		{1, 2},
+		{3, 0},
+		{10, 0},
+	}
+	for _, test := range tests {
+		plays := pbp.get(1001, test.since)
+		if plays == nil || len(plays.Plays) != test.expected || plays.LastSeq != 3 {
+			t.Errorf("since %d: plays %+v, expected %d plays", test.since, plays, test.expected)
+		}
+	}
+	if plays := pbp.get(1002, 0); plays != nil {
+		t.Errorf("plays %+v for an unknown game", plays)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	policyPreferPriority   = "prefer_priority"
+	policyPreferConfidence = "prefer_confidence"
+	policyPreferMonotonic  = "prefer_monotonic"
+)
+
+type candidate struct {
+	source      string
+	game        model.LiveGame
+	stale       bool
+	lastChanged time.Time
+}
+
+// reconciler compares the data from all available sources for a game
+type reconciler struct {
+	mu          sync.Mutex
+	lastServed  map[string]model.LiveGame
+	disagreeing map[string]bool
+}
+
+func newReconciler() *reconciler {
+	return &reconciler{lastServed: make(map[string]model.LiveGame), disagreeing: make(map[string]bool)}
+}
+
+// prune drops the state of the games which are not live
+func (reconciler *reconciler) prune(live map[string]bool) {
+	reconciler.mu.Lock()
+	defer reconciler.mu.Unlock()
+
+	for gameID := range reconciler.lastServed {
+		if !live[gameID] {
+			delete(reconciler.lastServed, gameID)
+		}
+	}
+	for gameID := range reconciler.disagreeing {
+		if !live[gameID] {
+			delete(reconciler.disagreeing, gameID)
+		}
+	}
+}
+
+// heldGame keeps a completed game as in progress until the sources agree on the final result
+type heldGame struct {
+	model.LiveGame
+}
+
+func (game *heldGame) GetIsComplete() bool {
+	return false
+}
+
+func (game *heldGame) Encode() map[string]string {
+	data := game.LiveGame.Encode()
+	data["IsComplete"] = strconv.FormatBool(false)
+	return data
+}
+
+// loadReconciled loads the data from all sources, flags disagreements between them and picks the result by the configured policy
+func (livestatsSource *sourceImpl) loadReconciled(item *sidearmModel.LiveGameItem, sources []string, threshold time.Duration, now time.Time) (model.LiveGame, error) {
+	var (
+		candidates []candidate
+		lastErr    error
+	)
+	for _, source := range sources {
+		result, err := livestatsSource.loadFromSource(source, item)
+		if err != nil {
+			log.Print(err.Error())
+			lastErr = err
+			continue
+		}
+		stale := livestatsSource.health.isStale(item, source, result, threshold, now)
+		lastChanged := livestatsSource.health.lastChanged(item, source)
+		candidates = append(candidates, candidate{source: source, game: result, stale: stale, lastChanged: lastChanged})
+	}
+	if len(candidates) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, errors.New("source: loadReconciled -> no source provided")
+	}
+
+	reconciliationConfig := livestatsSource.config.ReconciliationConfig
+	agree := livestatsSource.reconciler.checkAgreement(item, candidates, livestatsSource.health, now)
+	selected := livestatsSource.reconciler.selectCandidate(item, candidates, reconciliationConfig.Policy)
+	livestatsSource.health.setActive(item, selected.source, sources, now)
+
+	result := selected.game
+	if reconciliationConfig.RequireAgreementForFinal && result.GetIsComplete() && (len(candidates) < 2 || !agree) {
+		log.Printf("source: loadReconciled -> %s is complete for game %s but the sources do not agree yet", selected.source, item.GameID)
+		result = &heldGame{LiveGame: result}
+	}
+
+	livestatsSource.reconciler.mu.Lock()
+	if result.GetIsComplete() && agree {
+		//the sources agree on the final result, so there is nothing to compare anymore
+		delete(livestatsSource.reconciler.lastServed, item.GameID)
+		delete(livestatsSource.reconciler.disagreeing, item.GameID)
+	} else {
+		livestatsSource.reconciler.lastServed[item.GameID] = result
+	}
+	livestatsSource.reconciler.mu.Unlock()
+	return result, nil
+}
+
+// checkAgreement compares score, period and completion between the sources and emits an event when it changes
+func (reconciler *reconciler) checkAgreement(item *sidearmModel.LiveGameItem, candidates []candidate, health *sourceHealth, now time.Time) bool {
+	var diff string
+	first := candidates[0]
+	for _, current := range candidates[1:] {
+		if diff = compareGames(first.game, current.game); len(diff) > 0 {
+			diff = fmt.Sprintf("%s and %s differ in %s", first.source, current.source, diff)
+			break
+		}
+	}
+
+	reconciler.mu.Lock()
+	defer reconciler.mu.Unlock()
+	disagreeing := len(diff) > 0
+	if disagreeing && !reconciler.disagreeing[item.GameID] {
+		health.addEvent(HealthEvent{GameID: item.GameID, Sport: item.Sport, Source: first.source, Type: "disagreement", Message: diff, Time: now})
+	} else if !disagreeing && reconciler.disagreeing[item.GameID] {
+		health.addEvent(HealthEvent{GameID: item.GameID, Sport: item.Sport, Source: first.source, Type: "agreement", Message: "the sources agree again", Time: now})
+	}
+	reconciler.disagreeing[item.GameID] = disagreeing
+	return !disagreeing
+}
+
+// selectCandidate picks the candidate by policy, the default is the first fresh source by priority
+func (reconciler *reconciler) selectCandidate(item *sidearmModel.LiveGameItem, candidates []candidate, policy string) candidate {
+	switch policy {
+	case policyPreferConfidence:
+		// the fresh source which has changed most recently is the most confident one
+		selected := candidates[0]
+		for _, current := range candidates[1:] {
+			if (selected.stale && !current.stale) || (selected.stale == current.stale && current.lastChanged.After(selected.lastChanged)) {
+				selected = current
+			}
+		}
+		return selected
+	case policyPreferMonotonic:
+		reconciler.mu.Lock()
+		last := reconciler.lastServed[item.GameID]
+		reconciler.mu.Unlock()
+		if last != nil {
+			for _, current := range candidates {
+				if !current.stale && current.game.GetHomeScore() >= last.GetHomeScore() && current.game.GetVisitingScore() >= last.GetVisitingScore() {
+					return current
+				}
+			}
+		}
+	case policyPreferPriority, "":
+	default:
+		log.Printf("source: selectCandidate -> not supported policy %s so prefer priority", policy)
+	}
+
+	for _, current := range candidates {
+		if !current.stale {
+			return current
+		}
+	}
+	return candidates[0]
+}
+
+// compareGames gives the description of the differences between the games or empty string if they agree
+func compareGames(first model.LiveGame, second model.LiveGame) string {
+	if first.GetHomeScore() != second.GetHomeScore() || first.GetVisitingScore() != second.GetVisitingScore() {
+		return fmt.Sprintf("score %d:%d vs %d:%d", first.GetHomeScore(), first.GetVisitingScore(), second.GetHomeScore(), second.GetVisitingScore())
+	}
+	if first.GetPeriod() != second.GetPeriod() {
+		return fmt.Sprintf("period %d vs %d", first.GetPeriod(), second.GetPeriod())
+	}
+	if first.GetIsComplete() != second.GetIsComplete() {
+		return fmt.Sprintf("completion %t vs %t", first.GetIsComplete(), second.GetIsComplete())
+	}
+	return ""
+}
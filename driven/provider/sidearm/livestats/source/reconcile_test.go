@@ -0,0 +1,141 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"testing"
+	"time"
+)
+
+func TestSelectCandidate(t *testing.T) {
+	item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football"}
+	now := time.Date(2023, 10, 21, 18, 0, 0, 0, time.UTC)
+	xmlFeed := candidate{source: "xml_feed", game: &testGame{homeScore: 7}, lastChanged: now.Add(-time.Minute)}
+	staleXMLFeed := candidate{source: "xml_feed", game: &testGame{homeScore: 7}, stale: true, lastChanged: now.Add(-5 * time.Minute)}
+	sidearm := candidate{source: "sidearm", game: &testGame{homeScore: 3}, lastChanged: now}
+	staleSidearm := candidate{source: "sidearm", game: &testGame{homeScore: 3}, stale: true, lastChanged: now}
+
+	tests := []struct {
+		name       string
+		policy     string
+		lastServed model.LiveGame
+		candidates []candidate
+		expected   string
+	}{
+		{"priority takes the first fresh source", policyPreferPriority, nil, []candidate{xmlFeed, sidearm}, "xml_feed"},
+		{"priority skips the stale source", policyPreferPriority, nil, []candidate{staleXMLFeed, sidearm}, "sidearm"},
+		{"priority takes the first source if all are stale", policyPreferPriority, nil, []candidate{staleXMLFeed, staleSidearm}, "xml_feed"},
+		{"empty policy is priority", "", nil, []candidate{xmlFeed, sidearm}, "xml_feed"},
+		{"unknown policy is priority", "unknown", nil, []candidate{xmlFeed, sidearm}, "xml_feed"},
+		{"confidence takes the latest change", policyPreferConfidence, nil, []candidate{xmlFeed, sidearm}, "sidearm"},
+		{"confidence prefers fresh to stale", policyPreferConfidence, nil, []candidate{xmlFeed, staleSidearm}, "xml_feed"},
+		{"monotonic skips the source going back", policyPreferMonotonic, &testGame{homeScore: 7}, []candidate{sidearm, xmlFeed}, "xml_feed"},
+		{"monotonic takes the first source not going back", policyPreferMonotonic, &testGame{homeScore: 3}, []candidate{sidearm, xmlFeed}, "sidearm"},
+		{"monotonic without served data is priority", policyPreferMonotonic, nil, []candidate{sidearm, xmlFeed}, "sidearm"},
+		{"monotonic falls back to priority if all go back", policyPreferMonotonic, &testGame{homeScore: 10}, []candidate{sidearm, xmlFeed}, "sidearm"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reconciler := newReconciler()
+			if test.lastServed != nil {
+				reconciler.lastServed[item.GameID] = test.lastServed
+			}
+			selected := reconciler.selectCandidate(item, test.candidates, test.policy)
+			if selected.source != test.expected {
+				t.Errorf("selected %s, expected %s", selected.source, test.expected)
+			}
+		})
+	}
+}
+
+func TestCheckAgreement(t *testing.T) {
+	item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football"}
+	now := time.Date(2023, 10, 21, 18, 0, 0, 0, time.UTC)
+	agreeing := []candidate{{source: "xml_feed", game: &testGame{homeScore: 7, period: 2}}, {source: "sidearm", game: &testGame{homeScore: 7, period: 2}}}
+	score := []candidate{{source: "xml_feed", game: &testGame{homeScore: 7, period: 2}}, {source: "sidearm", game: &testGame{homeScore: 3, period: 2}}}
+	period := []candidate{{source: "xml_feed", game: &testGame{homeScore: 7, period: 3}}, {source: "sidearm", game: &testGame{homeScore: 7, period: 2}}}
+	completion := []candidate{{source: "xml_feed", game: &testGame{homeScore: 7, complete: true}}, {source: "sidearm", game: &testGame{homeScore: 7}}}
+
+	tests := []struct {
+		name   string
+		loads  [][]candidate
+		agree  bool
+		events []string
+	}{
+		{"agreeing sources", [][]candidate{agreeing}, true, nil},
+		{"single source agrees", [][]candidate{agreeing[:1]}, true, nil},
+		{"different score", [][]candidate{score}, false, []string{"disagreement"}},
+		{"different period", [][]candidate{period}, false, []string{"disagreement"}},
+		{"different completion", [][]candidate{completion}, false, []string{"disagreement"}},
+		{"the disagreement is emitted once", [][]candidate{score, period}, false, []string{"disagreement"}},
+		{"agreement after disagreement", [][]candidate{score, agreeing}, true, []string{"disagreement", "agreement"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reconciler := newReconciler()
+			health := newSourceHealth()
+			var agree bool
+			for _, candidates := range test.loads {
+				agree = reconciler.checkAgreement(item, candidates, health, now)
+			}
+			if agree != test.agree {
+				t.Errorf("agree %t, expected %t", agree, test.agree)
+			}
+			events := health.healthEvents()
+			if len(events) != len(test.events) {
+				t.Fatalf("events %+v, expected %v", events, test.events)
+			}
+			for i, event := range events {
+				if event.Type != test.events[i] {
+					t.Errorf("event %d is %s, expected %s", i, event.Type, test.events[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHeldGame(t *testing.T) {
+	game := &heldGame{LiveGame: &testGame{id: 1001, started: true, complete: true, homeScore: 21, visitingScore: 14}}
+	if game.GetIsComplete() || game.Encode()["IsComplete"] != "false" {
+		t.Error("the held game is complete")
+	}
+	if game.GetHomeScore() != 21 || game.Encode()["HomeScore"] != "21" {
+		t.Error("the held game lost the score")
+	}
+}
+
+func TestReconcilerPrune(t *testing.T) {
+	reconciler := newReconciler()
+	reconciler.lastServed["1001"] = &testGame{id: 1001}
+	reconciler.lastServed["1002"] = &testGame{id: 1002}
+	reconciler.disagreeing["1001"] = true
+	reconciler.disagreeing["1002"] = true
+
+	reconciler.prune(map[string]bool{"1002": true})
+	if _, exists := reconciler.lastServed["1001"]; exists || reconciler.disagreeing["1001"] {
+		t.Error("the state of the not live game is kept")
+	}
+	if _, exists := reconciler.lastServed["1002"]; !exists || !reconciler.disagreeing["1002"] {
+		t.Error("the state of the live game is dropped")
+	}
+}
+
+func TestReconciliationDisabledByDefault(t *testing.T) {
+	if NewConfig().ReconciliationConfig.Enabled {
+		t.Error("the reconciliation is enabled by default")
+	}
+}
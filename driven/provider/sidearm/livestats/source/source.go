@@ -16,15 +16,27 @@ package source
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"sport/core/model"
 	sidearmModel "sport/driven/provider/sidearm/model"
+	"time"
 )
 
+// Storage persists the final box scores and plays of the games
+type Storage interface {
+	LoadState(name string, value interface{}) error
+	SaveState(name string, value interface{}) error
+}
+
 // Source represents the source package interface
 type Source interface {
 	UpdateConfig(config Config)
 	LoadData(item *sidearmModel.LiveGameItem) (model.LiveGame, error)
+	HealthEvents() []HealthEvent
+	PruneGames(items []*sidearmModel.LiveGameItem)
+	BoxScore(gameID int) *model.GameBoxScore
+	Plays(gameID int, since int) *model.GamePlays
 }
 
 type sourceImpl struct {
@@ -33,16 +45,23 @@ type sourceImpl struct {
 	xmlFootbalSource    xmlFootballSource
 	xmlBasketballSource xmlBasketballSource
 	xmlVolleyballSource xmlVolleyballSource
+	health              *sourceHealth
+	reconciler          *reconciler
+	boxScores           *boxScores
+	plays               *playByPlay
 }
 
 // New create new source instance
-func New(config Config, ftpHost string, ftpUser string, ftpPassword string) Source {
+func New(config Config, ftpHost string, ftpUser string, ftpPassword string, storage Storage) Source {
+	boxScores := newBoxScores(storage)
+	plays := newPlayByPlay(storage)
 	sidearmSource := newSidearmSource(config)
-	xmlFootballSource := newXMLFootballSource(config, ftpHost, ftpUser, ftpPassword)
-	xmlBasketballSource := newXMLBasketballSource(config, ftpHost, ftpUser, ftpPassword)
-	xmlVolleyballSource := newXMLVolleyballSource(config, ftpHost, ftpUser, ftpPassword)
+	xmlFootballSource := newXMLFootballSource(config, ftpHost, ftpUser, ftpPassword, boxScores, plays)
+	xmlBasketballSource := newXMLBasketballSource(config, ftpHost, ftpUser, ftpPassword, boxScores, plays)
+	xmlVolleyballSource := newXMLVolleyballSource(config, ftpHost, ftpUser, ftpPassword, boxScores)
 	return &sourceImpl{config: config, sidearm: sidearmSource, xmlFootbalSource: xmlFootballSource,
-		xmlBasketballSource: xmlBasketballSource, xmlVolleyballSource: xmlVolleyballSource}
+		xmlBasketballSource: xmlBasketballSource, xmlVolleyballSource: xmlVolleyballSource, health: newSourceHealth(), reconciler: newReconciler(),
+		boxScores: boxScores, plays: plays}
 }
 
 func (livestatsSource *sourceImpl) UpdateConfig(config Config) {
@@ -59,42 +78,111 @@ func (livestatsSource *sourceImpl) LoadData(item *sidearmModel.LiveGameItem) (mo
 	sport := item.Sport
 	home := item.Home
 	sources := livestatsSource.config.GetLivestatsSource(sport, home)
+	threshold := livestatsSource.config.GetStaleThreshold()
 	log.Printf("source: LoadData -> sources:%s sport:%s gameId:%s", sources, item.Sport, item.GameID)
 
-	//get the live data from the sources by priority
-	for index, source := range sources {
-		if source == "sidearm" {
-			result, err := livestatsSource.sidearm.load(item)
-			if err == nil {
-				return result, nil
-			}
-
-			//error
-			log.Print(err.Error())
-			isLast := (len(sources) - 1) == index
-			if isLast {
-				log.Printf("source: LoadData -> it is last source so return error")
-				return nil, err
+	result, err := livestatsSource.loadFromSources(item, sources, threshold, time.Now())
+	if err == nil && result.GetIsComplete() {
+		//the feeds of a completed game are not stale anymore, so there is nothing to track for it
+		livestatsSource.health.forget(item.GameID)
+	}
+	return result, err
+}
 
-			}
-		} else if source == "xml_feed" {
-			result, err := livestatsSource.loadFromXML(item)
-			if err == nil {
-				return result, nil
-			}
+// loadFromSources gives the live data from the first fresh source or the reconciled data from all the sources
+func (livestatsSource *sourceImpl) loadFromSources(item *sidearmModel.LiveGameItem, sources []string, threshold time.Duration, now time.Time) (model.LiveGame, error) {
+	if livestatsSource.config.ReconciliationConfig.Enabled && len(sources) > 1 {
+		//compare the live data from all the sources
+		return livestatsSource.loadReconciled(item, sources, threshold, now)
+	}
 
-			//error
+	//get the live data from the sources by priority
+	var (
+		lastErr        error
+		fallback       model.LiveGame
+		fallbackSource string
+	)
+	for _, source := range sources {
+		result, err := livestatsSource.loadFromSource(source, item)
+		if err != nil {
 			log.Print(err.Error())
-			isLast := (len(sources) - 1) == index
-			if isLast {
-				log.Printf("source: LoadData -> it is last source so return error")
-				return nil, err
+			lastErr = err
+			continue
+		}
+
+		//skip the source if its data is frozen while the game clock is running
+		if livestatsSource.health.isStale(item, source, result, threshold, now) {
+			log.Printf("source: LoadData -> %s is stale so try the next source", source)
+			if fallback == nil {
+				fallback = result
+				fallbackSource = source
 			}
+			continue
 		}
+
+		livestatsSource.health.setActive(item, source, sources, now)
+		return result, nil
+	}
+
+	//all the available sources are stale, so give the one with the highest priority
+	if fallback != nil {
+		log.Printf("source: LoadData -> all sources are stale so return %s", fallbackSource)
+		livestatsSource.health.setActive(item, fallbackSource, sources, now)
+		return fallback, nil
+	}
+	if lastErr != nil {
+		log.Printf("source: LoadData -> all sources failed so return the last error")
+		return nil, lastErr
 	}
 	return nil, errors.New("source: LoadData -> no source provided")
 }
 
+// HealthEvents gives the latest source health events
+func (livestatsSource *sourceImpl) HealthEvents() []HealthEvent {
+	return livestatsSource.health.healthEvents()
+}
+
+// PruneGames drops the source health and the reconciliation state of the games which are not in the live items anymore
+func (livestatsSource *sourceImpl) PruneGames(items []*sidearmModel.LiveGameItem) {
+	live := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item != nil {
+			live[item.GameID] = true
+		}
+	}
+	livestatsSource.health.prune(live)
+	livestatsSource.reconciler.prune(live)
+}
+
+// BoxScore gives the last box score of the game from the xml feed, it gives nil if there is no box score for the game
+func (livestatsSource *sourceImpl) BoxScore(gameID int) *model.GameBoxScore {
+	return livestatsSource.boxScores.get(gameID)
+}
+
+// Plays gives the plays of the game from the xml feed after the since sequence number, it gives nil if there are no plays for the game
+func (livestatsSource *sourceImpl) Plays(gameID int, since int) *model.GamePlays {
+	return livestatsSource.plays.get(gameID, since)
+}
+
+func (livestatsSource *sourceImpl) loadFromSource(source string, item *sidearmModel.LiveGameItem) (model.LiveGame, error) {
+	var (
+		result model.LiveGame
+		err    error
+	)
+	switch source {
+	case "sidearm":
+		result, err = livestatsSource.sidearm.load(item)
+	case "xml_feed":
+		result, err = livestatsSource.loadFromXML(item)
+	default:
+		return nil, fmt.Errorf("source: loadFromSource -> not supported source %s", source)
+	}
+	if err == nil && result == nil {
+		err = fmt.Errorf("source: loadFromSource -> no data from %s for %s", source, item.Sport)
+	}
+	return result, err
+}
+
 func (livestatsSource *sourceImpl) loadFromXML(item *sidearmModel.LiveGameItem) (model.LiveGame, error) {
 	switch item.Sport {
 	case "football":
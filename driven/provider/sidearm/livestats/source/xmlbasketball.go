@@ -126,16 +126,8 @@ type xmlBasketballStatus struct {
 
 type xmlBasketballTeam struct {
 	XMLName   xml.Name               `xml:"team"`
-	VH        string                 `xml:"vh,attr"`
-	Name      string                 `xml:"name,attr"`
 	Linescore xmlBasketballLinescore `xml:"linescore"`
-	Players   []xmlBasketballPlayer  `xml:"player"`
-}
-
-type xmlBasketballPlayer struct {
-	XMLName   xml.Name `xml:"player"`
-	Name      string   `xml:"name,attr"`
-	Checkname string   `xml:"checkname,attr"`
+	xmlTeamStats
 }
 
 type xmlBasketballLinescore struct {
@@ -144,8 +136,10 @@ type xmlBasketballLinescore struct {
 }
 
 type xmlBasketballSource struct {
-	config  Config
-	ftpConn ftpConn
+	config    Config
+	ftpConn   ftpConn
+	boxScores *boxScores
+	plays     *playByPlay
 }
 
 type xmlBasketballPlays struct {
@@ -175,12 +169,16 @@ type xmlBasketballPlay struct {
 	Action    string   `xml:"action,attr"`
 	Type      string   `xml:"type,attr"`
 	Side      string   `xml:"side,attr"`
+	Vscore    string   `xml:"vscore,attr"`
+	Hscore    string   `xml:"hscore,attr"`
 }
 
-func newXMLBasketballSource(config Config, ftpHost string, ftpUser string, ftpPassword string) xmlBasketballSource {
+func newXMLBasketballSource(config Config, ftpHost string, ftpUser string, ftpPassword string, boxScores *boxScores, plays *playByPlay) xmlBasketballSource {
 	var xmlBasketballSource xmlBasketballSource
 	xmlBasketballSource.config = config
 	xmlBasketballSource.ftpConn = newFTPConn(ftpHost, ftpUser, ftpPassword)
+	xmlBasketballSource.boxScores = boxScores
+	xmlBasketballSource.plays = plays
 	return xmlBasketballSource
 }
 
@@ -195,10 +193,14 @@ func (xmlBasketballSource *xmlBasketballSource) load(item *sidearmModel.LiveGame
 	if err != nil {
 		return nil, err
 	}
+	return xmlBasketballSource.loadXML(item, xmlData)
+}
 
+// loadXML constructs the game from the downloaded xml data, it is separated from the download so the recorded xml could be replayed
+func (xmlBasketballSource *xmlBasketballSource) loadXML(item *sidearmModel.LiveGameItem, xmlData []byte) (model.LiveGame, error) {
 	//2. unmarshal
 	var xmlBasketballGame *xmlBasketballGame
-	err = xml.Unmarshal(xmlData, &xmlBasketballGame)
+	err := xml.Unmarshal(xmlData, &xmlBasketballGame)
 	if err != nil {
 		return nil, err
 	}
@@ -233,6 +235,16 @@ func (xmlBasketballSource *xmlBasketballSource) load(item *sidearmModel.LiveGame
 	//construct custom data
 	xmlFeedGame.customData = xmlBasketballSource.constructCustomData(xmlBasketballGame, phase, item.Sport)
 
+	//keep the box score
+	teams := make([]xmlTeamStats, len(xmlBasketballGame.Teams))
+	for i, team := range xmlBasketballGame.Teams {
+		teams[i] = team.xmlTeamStats
+	}
+	xmlBasketballSource.boxScores.set(xmlFeedGame.gameID, buildBoxScore(item, teams, xmlFeedGame.isComplete))
+
+	//keep the play by play
+	xmlBasketballSource.plays.set(item, xmlFeedGame.gameID, xmlBasketballSource.constructPlays(xmlBasketballGame), xmlFeedGame.isComplete)
+
 	return &xmlFeedGame, nil
 }
 
@@ -298,14 +310,7 @@ func (xmlBasketballSource *xmlBasketballSource) formatPlay(xmlData *xmlBasketbal
 	if len(play.Team) > 0 {
 		result = result + play.Team + " team,"
 	}
-	if len(play.Checkname) > 0 && play.Checkname != "TEAM" {
-		player := xmlBasketballSource.findPlayer(play.VH, play.Checkname, xmlData)
-		var playerLabel string
-		if player != nil {
-			playerLabel = player.Name
-		} else {
-			playerLabel = play.Checkname
-		}
+	if playerLabel := xmlBasketballSource.getPlayerLabel(xmlData, play); len(playerLabel) > 0 {
 		result = result + " " + playerLabel + ","
 	}
 	if len(play.Action) > 0 {
@@ -324,7 +329,38 @@ func (xmlBasketballSource *xmlBasketballSource) formatPlay(xmlData *xmlBasketbal
 	return result
 }
 
-func (xmlBasketballSource *xmlBasketballSource) findPlayer(vh string, checkname string, xmlData *xmlBasketballGame) *xmlBasketballPlayer {
+// getPlayerLabel gives the name of the player of the play, it is empty if the play is not for a player
+func (xmlBasketballSource *xmlBasketballSource) getPlayerLabel(xmlData *xmlBasketballGame, play *xmlBasketballPlay) string {
+	if len(play.Checkname) == 0 || play.Checkname == "TEAM" {
+		return ""
+	}
+	player := xmlBasketballSource.findPlayer(play.VH, play.Checkname, xmlData)
+	if player != nil {
+		return player.Name
+	}
+	return play.Checkname
+}
+
+// constructPlays gives all the plays of the game in their order
+func (xmlBasketballSource *xmlBasketballSource) constructPlays(xmlData *xmlBasketballGame) []feedPlay {
+	var plays []feedPlay
+	for _, period := range xmlData.Plays.Periods {
+		periodNumber, _ := strconv.Atoi(period.Number)
+		for i := range period.Plays {
+			xmlPlay := &period.Plays[i]
+			play := model.Play{Period: periodNumber, Clock: xmlPlay.Time, Home: getPlayTeam(xmlPlay.VH), Player: xmlBasketballSource.getPlayerLabel(xmlData, xmlPlay),
+				Uni: xmlPlay.UNI, Action: xmlPlay.Action, Type: xmlPlay.Type, Text: strings.TrimSpace(xmlBasketballSource.formatPlay(xmlData, xmlPlay)),
+				HomeScore: getPlayScore(xmlPlay.Hscore), VisitingScore: getPlayScore(xmlPlay.Vscore)}
+			//the stat crew gives the score only for the scoring plays
+			play.Scoring = play.HomeScore != nil || play.VisitingScore != nil
+			key := strings.Join([]string{period.Number, xmlPlay.VH, xmlPlay.Time, xmlPlay.Checkname, xmlPlay.Action, xmlPlay.Type}, "|")
+			plays = append(plays, feedPlay{key: key, play: play})
+		}
+	}
+	return plays
+}
+
+func (xmlBasketballSource *xmlBasketballSource) findPlayer(vh string, checkname string, xmlData *xmlBasketballGame) *xmlPlayerStats {
 	team := xmlBasketballSource.findTeam(vh, xmlData)
 	if team == nil {
 		log.Println("xmlbasketball findPlayer -> no team")
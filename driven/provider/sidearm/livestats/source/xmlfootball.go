@@ -112,6 +112,7 @@ type xmlFootballGame struct {
 	Venue     xmlFootballVenue  `xml:"venue"`
 	Plays     xmlFootballPlays  `xml:"plays"`
 	Scores    xmlFootballScores `xml:"scores"`
+	Teams     []xmlTeamStats    `xml:"team"`
 }
 
 type xmlFootballVenue struct {
@@ -150,6 +151,10 @@ type xmlFootballPlay struct {
 	Vscore  string   `xml:"vscore,attr"`
 	Hscore  string   `xml:"hscore,attr"`
 	Clock   string   `xml:"clock,attr"`
+	Context string   `xml:"context,attr"` // the team with the ball, down, to go and spot - "V,1,10,V25"
+	PlayID  string   `xml:"playid,attr"`
+	Type    string   `xml:"type,attr"`
+	Text    string   `xml:"text,attr"`
 }
 
 type xmlFootballQtrScore struct {
@@ -168,14 +173,18 @@ type xmlFootballDowntogo struct {
 }
 
 type xmlFootballSource struct {
-	config  Config
-	ftpConn ftpConn
+	config    Config
+	ftpConn   ftpConn
+	boxScores *boxScores
+	plays     *playByPlay
 }
 
-func newXMLFootballSource(config Config, ftpHost string, ftpUser string, ftpPassword string) xmlFootballSource {
+func newXMLFootballSource(config Config, ftpHost string, ftpUser string, ftpPassword string, boxScores *boxScores, plays *playByPlay) xmlFootballSource {
 	var xmlFootballSource xmlFootballSource
 	xmlFootballSource.config = config
 	xmlFootballSource.ftpConn = newFTPConn(ftpHost, ftpUser, ftpPassword)
+	xmlFootballSource.boxScores = boxScores
+	xmlFootballSource.plays = plays
 	return xmlFootballSource
 }
 
@@ -190,10 +199,14 @@ func (xmlFootballSource *xmlFootballSource) load(item *sidearmModel.LiveGameItem
 	if err != nil {
 		return nil, err
 	}
+	return xmlFootballSource.loadXML(item, xmlData)
+}
 
+// loadXML constructs the game from the downloaded xml data, it is separated from the download so the recorded xml could be replayed
+func (xmlFootballSource *xmlFootballSource) loadXML(item *sidearmModel.LiveGameItem, xmlData []byte) (model.LiveGame, error) {
 	//2. unmarshal
 	var xmlFootballGame *xmlFootballGame
-	err = xml.Unmarshal(xmlData, &xmlFootballGame)
+	err := xml.Unmarshal(xmlData, &xmlFootballGame)
 	if err != nil {
 		return nil, err
 	}
@@ -229,6 +242,12 @@ func (xmlFootballSource *xmlFootballSource) load(item *sidearmModel.LiveGameItem
 	xmlFeedGame.period = xmlFootballSource.constructRegularPeriod(phase)
 	xmlFeedGame.clockSeconds = xmlFootballSource.constructRegularClock(clock)
 
+	//keep the box score
+	xmlFootballSource.boxScores.set(xmlFeedGame.gameID, buildBoxScore(item, xmlFootballGame.Teams, xmlFeedGame.isComplete))
+
+	//keep the play by play
+	xmlFootballSource.plays.set(item, xmlFeedGame.gameID, xmlFootballSource.constructPlays(xmlFootballGame), xmlFeedGame.isComplete)
+
 	return &xmlFeedGame, nil
 }
 
@@ -426,6 +445,42 @@ func (xmlFootballSource *xmlFootballSource) getLastPlay(phase string, downtogo *
 	return downtogo.LastPlay
 }
 
+// constructPlays gives all the plays of the game in their order
+func (xmlFootballSource *xmlFootballSource) constructPlays(xmlData *xmlFootballGame) []feedPlay {
+	var (
+		plays         []feedPlay
+		homeScore     int
+		visitingScore int
+	)
+	for _, quarter := range xmlData.Plays.Quarters {
+		quarterNumber, _ := strconv.Atoi(quarter.Number)
+		for _, xmlPlay := range quarter.Plays {
+			hasBall := strings.Split(xmlPlay.Context, ",")[0]
+			play := model.Play{Period: quarterNumber, Clock: xmlPlay.Clock, Home: getPlayTeam(hasBall), Type: xmlPlay.Type, Text: xmlPlay.Text,
+				Scoring: xmlPlay.Score == "Y", HomeScore: getPlayScore(xmlPlay.Hscore), VisitingScore: getPlayScore(xmlPlay.Vscore)}
+
+			//the team without the ball scores on the defense and the special teams plays, so the score tells who has scored
+			if play.HomeScore != nil && play.VisitingScore != nil {
+				homeScored := *play.HomeScore > homeScore
+				visitingScored := *play.VisitingScore > visitingScore
+				if play.Scoring && homeScored != visitingScored {
+					play.Home = &homeScored
+				}
+				homeScore, visitingScore = *play.HomeScore, *play.VisitingScore
+			}
+
+			//the play id identifies the play, the clock and the context are used if it is missing. The text is not
+			//used as the stat crew could edit it.
+			key := quarter.Number + "|" + xmlPlay.PlayID
+			if len(xmlPlay.PlayID) == 0 {
+				key = strings.Join([]string{quarter.Number, xmlPlay.Clock, xmlPlay.Context, xmlPlay.Type}, "|")
+			}
+			plays = append(plays, feedPlay{key: key, play: play})
+		}
+	}
+	return plays
+}
+
 func (xmlFootballSource *xmlFootballSource) getDisplayPhase(phase string) string {
 	return xmlFootballSource.config.GetFootballPhaseLabel(phase)
 }
@@ -99,6 +99,7 @@ type xmlVolleyballGame struct {
 	Generated string               `xml:"generated,attr"`
 	Venue     xmlVolleyballVenue   `xml:"venue"`
 	Status    *xmlVolleyballStatus `xml:"status"`
+	Teams     []xmlTeamStats       `xml:"team"`
 }
 
 type xmlVolleyballVenue struct {
@@ -129,8 +130,9 @@ type volleyballCustomData struct {
 }
 
 type xmlVolleyballSource struct {
-	config  Config
-	ftpConn ftpConn
+	config    Config
+	ftpConn   ftpConn
+	boxScores *boxScores
 }
 
 func (xmlVolleyballSource *xmlVolleyballSource) updateConfig(config Config) {
@@ -144,10 +146,14 @@ func (xmlVolleyballSource *xmlVolleyballSource) load(item *sidearmModel.LiveGame
 	if err != nil {
 		return nil, err
 	}
+	return xmlVolleyballSource.loadXML(item, xmlData)
+}
 
+// loadXML constructs the game from the downloaded xml data, it is separated from the download so the recorded xml could be replayed
+func (xmlVolleyballSource *xmlVolleyballSource) loadXML(item *sidearmModel.LiveGameItem, xmlData []byte) (model.LiveGame, error) {
 	//2. unmarshal
 	var xmlVolleyballGame *xmlVolleyballGame
-	err = xml.Unmarshal(xmlData, &xmlVolleyballGame)
+	err := xml.Unmarshal(xmlData, &xmlVolleyballGame)
 	if err != nil {
 		return nil, err
 	}
@@ -178,6 +184,9 @@ func (xmlVolleyballSource *xmlVolleyballSource) load(item *sidearmModel.LiveGame
 	//construct custom data
 	xmlFeedGame.customData = xmlVolleyballSource.constructCustomData(xmlVolleyballGame, xmlFeedGame.hasStarted, xmlFeedGame.isComplete)
 
+	//keep the box score
+	xmlVolleyballSource.boxScores.set(xmlFeedGame.gameID, buildBoxScore(item, xmlVolleyballGame.Teams, xmlFeedGame.isComplete))
+
 	return &xmlFeedGame, nil
 }
 
@@ -406,9 +415,10 @@ func (xmlVolleyballSource *xmlVolleyballSource) printXMLVolleyballGame(xmlVolley
 		status.Complete, status.VSCore, status.HScore, status.Game, status.Serving, status.VPoints, status.HPoints)
 }
 
-func newXMLVolleyballSource(config Config, ftpHost string, ftpUser string, ftpPassword string) xmlVolleyballSource {
+func newXMLVolleyballSource(config Config, ftpHost string, ftpUser string, ftpPassword string, boxScores *boxScores) xmlVolleyballSource {
 	var xmlVolleyballSource xmlVolleyballSource
 	xmlVolleyballSource.config = config
 	xmlVolleyballSource.ftpConn = newFTPConn(ftpHost, ftpUser, ftpPassword)
+	xmlVolleyballSource.boxScores = boxScores
 	return xmlVolleyballSource
 }
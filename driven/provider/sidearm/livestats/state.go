@@ -0,0 +1,226 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package livestats
+
+import (
+	"log"
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strconv"
+	"time"
+)
+
+// GameState represents the state of a game
+type GameState string
+
+const (
+	// StateScheduled the game is scheduled but not about to start
+	StateScheduled GameState = "scheduled"
+	// StatePregame the game is about to start
+	StatePregame GameState = "pregame"
+	// StateLive the game is in progress
+	StateLive GameState = "live"
+	// StateIntermission the game is between periods
+	StateIntermission GameState = "intermission"
+	// StateFinal the game is complete
+	StateFinal GameState = "final"
+	// StateCorrected the final score of the game was corrected
+	StateCorrected GameState = "corrected"
+	// StatePostponed the game is postponed
+	StatePostponed GameState = "postponed"
+	// StateCancelled the game is cancelled
+	StateCancelled GameState = "cancelled"
+)
+
+// validTransitions contains the states which could follow every state. Staying in the same state is always valid.
+var validTransitions = map[GameState][]GameState{
+	StateScheduled:    {StatePregame, StateLive, StateIntermission, StateFinal, StatePostponed, StateCancelled},
+	StatePregame:      {StateLive, StateIntermission, StateFinal, StatePostponed, StateCancelled},
+	StateLive:         {StateIntermission, StateFinal, StatePostponed, StateCancelled},
+	StateIntermission: {StateLive, StateFinal, StatePostponed, StateCancelled},
+	StateFinal:        {StateCorrected},
+	StateCorrected:    {},
+	StatePostponed:    {StateScheduled, StatePregame, StateLive, StateCancelled},
+	StateCancelled:    {},
+}
+
+// gameTracker keeps the state and the last accepted snapshot of a game
+type gameTracker struct {
+	state             GameState
+	timed             bool
+	homeScore         int
+	visitingScore     int
+	period            int
+	leader            int // 1 - home, -1 - visiting, 0 - nobody has led yet
+	scoreChanges      int // how many times the score has changed, it tells apart the plays with the same score
+	overtimeNotified  bool
+	closeGameNotified bool
+}
+
+// stateTransition describes the result of processing a new snapshot of a game
+type stateTransition struct {
+	from      GameState
+	to        GameState
+	first     bool
+	corrected bool
+	rejected  bool
+	previous  gameTracker
+	current   gameTracker
+	alerts    []scoringAlert
+}
+
+// isValidTransition checks if the game could move from one state to another
+func isValidTransition(from GameState, to GameState) bool {
+	if from == to {
+		return true
+	}
+	for _, state := range validTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// deriveState gives the state of the game based on the loaded data and the schedule item
+func deriveState(game model.LiveGame, item *sidearmModel.LiveGameItem, timed bool, now time.Time) GameState {
+	switch item.Status {
+	case "C":
+		return StateCancelled
+	case "P":
+		return StatePostponed
+	}
+	if game.GetIsComplete() {
+		return StateFinal
+	}
+	if game.GetHasStarted() {
+		//only the sports with a clock have intermissions - the clock stays at zero between the periods
+		if timed && game.GetClockSeconds() <= 0 {
+			return StateIntermission
+		}
+		return StateLive
+	}
+	if !item.Time.IsZero() && now.Before(item.Time.Add(-5*time.Minute)) {
+		return StateScheduled
+	}
+	return StatePregame
+}
+
+// updateGameState moves the game to the state for the new snapshot if the transition is valid
+func (stats *livestats) updateGameState(game model.LiveGame, item *sidearmModel.LiveGameItem) stateTransition {
+	gameID := game.GetGameID()
+	tracker := stats.states[gameID]
+	if tracker == nil {
+		timed := game.GetClockSeconds() > 0
+		state := deriveState(game, item, timed, time.Now())
+		tracker = &gameTracker{state: state, timed: timed, homeScore: game.GetHomeScore(),
+			visitingScore: game.GetVisitingScore(), period: game.GetPeriod(), leader: getLeader(game)}
+		stats.states[gameID] = tracker
+		log.Printf("LiveStats: updateGameState -> game %d starts tracking in state %s\n", gameID, state)
+		return stateTransition{to: state, first: true, previous: *tracker, current: *tracker}
+	}
+
+	tracker.timed = tracker.timed || game.GetClockSeconds() > 0
+	newState := deriveState(game, item, tracker.timed, time.Now())
+	scoreChanged := game.GetHomeScore() != tracker.homeScore || game.GetVisitingScore() != tracker.visitingScore
+	scoreDropped := game.GetHomeScore() < tracker.homeScore || game.GetVisitingScore() < tracker.visitingScore
+
+	transition := stateTransition{from: tracker.state, previous: *tracker, current: *tracker}
+	isFinal := tracker.state == StateFinal || tracker.state == StateCorrected
+	if isFinal && newState == StateFinal && scoreChanged {
+		//the stat crew has changed the score after the end of the game
+		newState = StateCorrected
+		transition.corrected = true
+	} else if isFinal && newState == StateFinal {
+		//keep the corrected state for the same score
+		newState = tracker.state
+	} else if scoreDropped && (newState == StateLive || newState == StateIntermission) {
+		//the stat crew has taken points back during the game
+		transition.corrected = true
+	}
+
+	if !isValidTransition(tracker.state, newState) {
+		log.Printf("LiveStats: updateGameState -> invalid transition for game %d from %s to %s, keep %s\n", gameID, tracker.state, newState, tracker.state)
+		transition.to = tracker.state
+		transition.rejected = true
+		return transition
+	}
+
+	if tracker.state != newState {
+		log.Printf("LiveStats: updateGameState -> game %d moved from %s to %s\n", gameID, tracker.state, newState)
+	}
+	if scoreChanged {
+		tracker.scoreChanges++
+	}
+	tracker.state = newState
+	tracker.homeScore = game.GetHomeScore()
+	tracker.visitingScore = game.GetVisitingScore()
+	tracker.period = game.GetPeriod()
+	if leader := getLeader(game); leader != 0 {
+		tracker.leader = leader
+	}
+	transition.to = newState
+	transition.current = *tracker
+
+	//the alerts which are sent once for the game are remembered by the tracker
+	transition.alerts, transition.current = stats.detectScoringAlerts(transition, game, item)
+	*tracker = transition.current
+	return transition
+}
+
+// pruneStates stops tracking the completed games which are not in the live items anymore
+func (stats *livestats) pruneStates(items []*sidearmModel.LiveGameItem) {
+	live := make(map[int]bool, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		if gameID, err := strconv.Atoi(item.GameID); err == nil {
+			live[gameID] = true
+		}
+	}
+	for gameID, tracker := range stats.states {
+		isDone := tracker.state == StateFinal || tracker.state == StateCorrected || tracker.state == StateCancelled
+		if isDone && !live[gameID] {
+			log.Printf("LiveStats: pruneStates -> game %d stops tracking in state %s\n", gameID, tracker.state)
+			delete(stats.states, gameID)
+		}
+	}
+}
+
+// getLeader gives 1 if the home team leads, -1 if the visiting team leads and 0 for a tie
+func getLeader(game model.LiveGame) int {
+	if game.GetHomeScore() > game.GetVisitingScore() {
+		return 1
+	} else if game.GetHomeScore() < game.GetVisitingScore() {
+		return -1
+	}
+	return 0
+}
+
+// needsGameStartedNotification checks if the transition starts the game
+func (transition stateTransition) needsGameStartedNotification() bool {
+	isInProgress := transition.to == StateLive || transition.to == StateIntermission
+	if transition.first {
+		return isInProgress
+	}
+	wasNotStarted := transition.from == StateScheduled || transition.from == StatePregame || transition.from == StatePostponed
+	return wasNotStarted && isInProgress
+}
+
+// needsGameEndedNotification checks if the transition ends the game
+func (transition stateTransition) needsGameEndedNotification() bool {
+	return !transition.first && transition.to == StateFinal && transition.from != StateFinal
+}
@@ -0,0 +1,241 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package livestats
+
+import (
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testGame is a live game with the values set by the test
+type testGame struct {
+	id            int
+	path          string
+	started       bool
+	complete      bool
+	clock         int
+	period        int
+	homeScore     int
+	visitingScore int
+}
+
+func (game *testGame) GetType() string       { return game.path }
+func (game *testGame) GetGameID() int        { return game.id }
+func (game *testGame) GetPath() string       { return game.path }
+func (game *testGame) GetHasStarted() bool   { return game.started }
+func (game *testGame) GetIsComplete() bool   { return game.complete }
+func (game *testGame) GetClockSeconds() int  { return game.clock }
+func (game *testGame) GetPeriod() int        { return game.period }
+func (game *testGame) GetHomeScore() int     { return game.homeScore }
+func (game *testGame) GetVisitingScore() int { return game.visitingScore }
+func (game *testGame) GetCustomData() string { return "" }
+
+func (game *testGame) Encode() map[string]string {
+	return map[string]string{"Type": game.path, "GameId": strconv.Itoa(game.id), "Path": game.path, "HasStarted": strconv.FormatBool(game.started),
+		"IsComplete": strconv.FormatBool(game.complete), "ClockSeconds": strconv.Itoa(game.clock), "Period": strconv.Itoa(game.period),
+		"HomeScore": strconv.Itoa(game.homeScore), "VisitingScore": strconv.Itoa(game.visitingScore)}
+}
+
+// snapshot gives a football game in progress with the score, the period and the clock
+func snapshot(homeScore int, visitingScore int, period int, clock int) *testGame {
+	return &testGame{id: 1001, path: "football", started: true, clock: clock, period: period, homeScore: homeScore, visitingScore: visitingScore}
+}
+
+// finalSnapshot gives a completed football game with the score
+func finalSnapshot(homeScore int, visitingScore int) *testGame {
+	return &testGame{id: 1001, path: "football", started: true, complete: true, period: 4, homeScore: homeScore, visitingScore: visitingScore}
+}
+
+func notStarted() *testGame {
+	return &testGame{id: 1001, path: "football"}
+}
+
+func TestIsValidTransition(t *testing.T) {
+	states := []GameState{StateScheduled, StatePregame, StateLive, StateIntermission, StateFinal, StateCorrected, StatePostponed, StateCancelled}
+	valid := map[GameState][]GameState{
+		StateScheduled:    {StatePregame, StateLive, StateIntermission, StateFinal, StatePostponed, StateCancelled},
+		StatePregame:      {StateLive, StateIntermission, StateFinal, StatePostponed, StateCancelled},
+		StateLive:         {StateIntermission, StateFinal, StatePostponed, StateCancelled},
+		StateIntermission: {StateLive, StateFinal, StatePostponed, StateCancelled},
+		StateFinal:        {StateCorrected},
+		StatePostponed:    {StateScheduled, StatePregame, StateLive, StateCancelled},
+	}
+	for _, from := range states {
+		for _, to := range states {
+			expected := from == to
+			for _, state := range valid[from] {
+				expected = expected || state == to
+			}
+			if isValidTransition(from, to) != expected {
+				t.Errorf("transition from %s to %s valid %t, expected %t", from, to, !expected, expected)
+			}
+		}
+	}
+}
+
+func TestDeriveState(t *testing.T) {
+	now := time.Date(2023, 10, 21, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		game     *testGame
+		status   string
+		start    time.Time
+		timed    bool
+		expected GameState
+	}{
+		{"cancelled status wins", snapshot(7, 0, 1, 600), "C", now, true, StateCancelled},
+		{"postponed status wins", finalSnapshot(7, 0), "P", now, true, StatePostponed},
+		{"complete game is final", finalSnapshot(7, 0), "", now, true, StateFinal},
+		{"running clock is live", snapshot(7, 0, 1, 600), "", now, true, StateLive},
+		{"clock at zero is intermission", snapshot(7, 0, 1, 0), "", now, true, StateIntermission},
+		{"not timed sport has no intermission", snapshot(7, 0, 1, 0), "", now, false, StateLive},
+		{"far before the start is scheduled", notStarted(), "", now.Add(time.Hour), true, StateScheduled},
+		{"just before the start is pregame", notStarted(), "", now.Add(3 * time.Minute), true, StatePregame},
+		{"unknown start is pregame", notStarted(), "", time.Time{}, true, StatePregame},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football", Status: test.status, Time: test.start}
+			if state := deriveState(test.game, item, test.timed, now); state != test.expected {
+				t.Errorf("state %s, expected %s", state, test.expected)
+			}
+		})
+	}
+}
+
+func TestUpdateGameState(t *testing.T) {
+	type step struct {
+		game      *testGame
+		status    string
+		to        GameState
+		started   bool
+		ended     bool
+		corrected bool
+		rejected  bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"game starts when it goes live", []step{
+			{game: notStarted(), to: StatePregame},
+			{game: snapshot(0, 0, 1, 900), to: StateLive, started: true},
+			{game: snapshot(7, 0, 1, 600), to: StateLive},
+		}},
+		{"first live snapshot starts the game", []step{
+			{game: snapshot(0, 0, 1, 900), to: StateLive, started: true},
+		}},
+		{"first final snapshot does not end the game", []step{
+			{game: finalSnapshot(21, 14), to: StateFinal},
+		}},
+		{"intermission and live again do not start the game again", []step{
+			{game: snapshot(7, 0, 1, 600), to: StateLive, started: true},
+			{game: snapshot(7, 0, 1, 0), to: StateIntermission},
+			{game: snapshot(7, 0, 2, 900), to: StateLive},
+		}},
+		{"game ends once", []step{
+			{game: snapshot(21, 14, 4, 30), to: StateLive, started: true},
+			{game: finalSnapshot(21, 14), to: StateFinal, ended: true},
+			{game: finalSnapshot(21, 14), to: StateFinal},
+		}},
+		{"changed final score is a correction", []step{
+			{game: snapshot(21, 14, 4, 30), to: StateLive, started: true},
+			{game: finalSnapshot(21, 14), to: StateFinal, ended: true},
+			{game: finalSnapshot(21, 17), to: StateCorrected, corrected: true},
+			{game: finalSnapshot(21, 17), to: StateCorrected},
+			{game: finalSnapshot(24, 17), to: StateCorrected, corrected: true},
+		}},
+		{"points taken back during the game are a correction", []step{
+			{game: snapshot(7, 0, 1, 600), to: StateLive, started: true},
+			{game: snapshot(6, 0, 1, 580), to: StateLive, corrected: true},
+		}},
+		{"final game does not go live again", []step{
+			{game: snapshot(21, 14, 4, 30), to: StateLive, started: true},
+			{game: finalSnapshot(21, 14), to: StateFinal, ended: true},
+			{game: snapshot(21, 14, 4, 30), to: StateFinal, rejected: true},
+		}},
+		{"cancelled game stays cancelled", []step{
+			{game: notStarted(), status: "C", to: StateCancelled},
+			{game: snapshot(0, 0, 1, 900), to: StateCancelled, rejected: true},
+		}},
+		{"postponed game starts later", []step{
+			{game: notStarted(), status: "P", to: StatePostponed},
+			{game: snapshot(0, 0, 1, 900), to: StateLive, started: true},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stats := &livestats{states: make(map[int]*gameTracker)}
+			for i, step := range test.steps {
+				item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football", Status: step.status}
+				transition := stats.updateGameState(step.game, item)
+				if transition.to != step.to {
+					t.Errorf("step %d: state %s, expected %s", i, transition.to, step.to)
+				}
+				if transition.needsGameStartedNotification() != step.started {
+					t.Errorf("step %d: started %t, expected %t", i, !step.started, step.started)
+				}
+				if transition.needsGameEndedNotification() != step.ended {
+					t.Errorf("step %d: ended %t, expected %t", i, !step.ended, step.ended)
+				}
+				if transition.corrected != step.corrected {
+					t.Errorf("step %d: corrected %t, expected %t", i, transition.corrected, step.corrected)
+				}
+				if transition.rejected != step.rejected {
+					t.Errorf("step %d: rejected %t, expected %t", i, transition.rejected, step.rejected)
+				}
+				if tracker := stats.states[1001]; tracker.state != step.to {
+					t.Errorf("step %d: tracked state %s, expected %s", i, tracker.state, step.to)
+				}
+			}
+		})
+	}
+}
+
+func TestRejectedTransitionKeepsTheSnapshot(t *testing.T) {
+	stats := &livestats{states: make(map[int]*gameTracker)}
+	item := &sidearmModel.LiveGameItem{GameID: "1001", Sport: "football"}
+	stats.updateGameState(finalSnapshot(21, 14), item)
+	stats.updateGameState(snapshot(28, 14, 4, 30), item)
+
+	tracker := stats.states[1001]
+	if tracker.homeScore != 21 || tracker.visitingScore != 14 || tracker.period != 4 {
+		t.Errorf("the rejected snapshot has changed the tracker %+v", tracker)
+	}
+}
+
+func TestPruneStates(t *testing.T) {
+	stats := &livestats{states: map[int]*gameTracker{
+		1: {state: StateFinal},
+		2: {state: StateCorrected},
+		3: {state: StateCancelled},
+		4: {state: StateLive},
+		5: {state: StateFinal},
+	}}
+	stats.pruneStates([]*sidearmModel.LiveGameItem{{GameID: "5"}, nil})
+
+	for _, gameID := range []int{1, 2, 3} {
+		if _, exists := stats.states[gameID]; exists {
+			t.Errorf("the done game %d which left the live items is tracked", gameID)
+		}
+	}
+	for _, gameID := range []int{4, 5} {
+		if _, exists := stats.states[gameID]; !exists {
+			t.Errorf("the game %d is not tracked", gameID)
+		}
+	}
+}
@@ -317,6 +317,7 @@ type LiveGameItem struct {
 	Sport        string
 	Home         bool
 	OpponentName string
+	Status       string
 }
 
 // GameItems structure
@@ -0,0 +1,205 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"sport/core/model"
+	"sport/driven/notifications"
+	"sport/driven/provider/sidearm/livestats/source"
+	"strings"
+	"time"
+)
+
+const (
+	newsSeenState  string = "news_seen"
+	newsSeenMaxAge        = time.Hour * 24 * 30
+)
+
+// newsRecord is what is known about a story which was already seen
+type newsRecord struct {
+	Hash    string    `json:"hash"`
+	PubDate string    `json:"pub_date"`
+	SeenAt  time.Time `json:"seen_at"`
+}
+
+func (p *Provider) loadNewsSeen() map[string]newsRecord {
+	seen := make(map[string]newsRecord)
+	if p.storage == nil {
+		return seen
+	}
+	err := p.storage.LoadState(newsSeenState, &seen)
+	if err != nil {
+		log.Printf("sidearm -> loadNewsSeen: failed to load seen news. Reason: %s", err.Error())
+	}
+	return seen
+}
+
+func (p *Provider) saveNewsSeen(seen map[string]newsRecord) {
+	if p.storage == nil {
+		return
+	}
+	err := p.storage.SaveState(newsSeenState, seen)
+	if err != nil {
+		log.Printf("sidearm -> saveNewsSeen: failed to save seen news. Reason: %s", err.Error())
+	}
+}
+
+// detectNewsChanges compares the stories with the seen ones and gives the new and the edited stories.
+// Only the stories published after the service start are new, the older ones are just marked as seen.
+func (p *Provider) detectNewsChanges(news []model.News, now time.Time) ([]model.News, []model.News) {
+	var created []model.News
+	var edited []model.News
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, item := range news {
+		hash := getNewsHash(item)
+		record, exists := p.newsSeen[item.ID]
+		if !exists {
+			pubDate, err := model.ParseNewsTime(item.PubDateUtc)
+			if err == nil && pubDate.After(p.startedAt) {
+				created = append(created, item)
+			} else {
+				log.Printf("sidearm -> detectNewsChanges: skip story %s published at %s, it is not newer than the service start", item.ID, item.PubDateUtc)
+			}
+		} else if record.Hash != hash {
+			edited = append(edited, item)
+		}
+		p.newsSeen[item.ID] = newsRecord{Hash: hash, PubDate: item.PubDateUtc, SeenAt: now}
+	}
+
+	for id, record := range p.newsSeen {
+		if now.Sub(record.SeenAt) > newsSeenMaxAge {
+			delete(p.newsSeen, id)
+		}
+	}
+	return created, edited
+}
+
+// notifyNews sends the new and the edited stories. When there are more new stories for a sport than the limit,
+// a digest is sent instead of them.
+func (p *Provider) notifyNews(created []model.News, edited []model.News) {
+	newsConfig := p.config.NotificationConfig.News
+
+	bySport := make(map[string][]model.News)
+	var sports []string
+	for _, item := range created {
+		if _, exists := bySport[item.Sport]; !exists {
+			sports = append(sports, item.Sport)
+		}
+		bySport[item.Sport] = append(bySport[item.Sport], item)
+	}
+	for _, sport := range sports {
+		items := bySport[sport]
+		if newsConfig.MaxPushesPerCheck > 0 && len(items) > newsConfig.MaxPushesPerCheck {
+			p.sendNewsDigest(sport, items)
+			continue
+		}
+		for _, item := range items {
+			log.Printf("sidearm -> notifyNews: Found new item: %s", item.ID)
+			p.sendNewsNotification(item, false)
+		}
+	}
+
+	for _, item := range edited {
+		if newsConfig.NotifyEdits {
+			log.Printf("sidearm -> notifyNews: Found edited item: %s", item.ID)
+			p.sendNewsNotification(item, true)
+		} else {
+			log.Printf("sidearm -> notifyNews: skip edited item: %s", item.ID)
+		}
+	}
+}
+
+// sendNewsDigest sends one notification for many new stories of a sport
+func (p *Provider) sendNewsDigest(sport string, items []model.News) {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	sort.Strings(ids)
+	log.Printf("sidearm -> sendNewsDigest: send digest for %d new items of %s", len(items), sport)
+
+	data := make((map[string]string))
+	data["type"] = "athletics_news"
+	data["sport"] = sport
+	data["news_ids"] = strings.Join(ids, ",")
+	data["click_action"] = "FLUTTER_NOTIFICATION_CLICK"
+	messageData := source.MessageData{Sport: sport, Category: items[0].Category, Title: items[0].Title, Count: len(items)}
+	key := fmt.Sprintf("news.digest.%s", getHash(strings.Join(ids, ",")))
+	p.sendNewsMessage(sport, "news_updates_digest_format", messageData, data, key)
+}
+
+// sendNewsNotification sends the new or the edited story
+func (p *Provider) sendNewsNotification(news model.News, edited bool) {
+	data := make((map[string]string))
+	data["type"] = "athletics_news_detail"
+	data["sport"] = news.Sport
+	data["news_id"] = news.ID
+	data["click_action"] = "FLUTTER_NOTIFICATION_CLICK"
+	messageData := source.MessageData{Sport: news.Sport, Category: news.Category, Title: news.Title}
+
+	bodyKey := "news_updates_body_content_format"
+	key := "news." + news.ID
+	if edited {
+		bodyKey = "news_updates_edited_body_format"
+		key = fmt.Sprintf("news.%s.%s", news.ID, getNewsHash(news))
+	}
+	p.sendNewsMessage(news.Sport, bodyKey, messageData, data, key)
+}
+
+// sendNewsMessage sends the message in every configured locale, the topics of the other than the default locale are tagged with the locale
+func (p *Provider) sendNewsMessage(sport string, bodyKey string, messageData source.MessageData, data map[string]string, key string) {
+	notificationConfig := p.config.NotificationConfig
+	// topic is "athletics.{sport_short_name}.notification.news"
+	topic := fmt.Sprintf("athletics.%s.notification.news", sport)
+
+	for _, locale := range notificationConfig.GetLocales() {
+		configGameMessages := notificationConfig.GetMessages(locale)
+		var msgTitle string
+		if len(messageData.Category) > 0 {
+			msgTitle = source.RenderMessage(configGameMessages, "news_updates_sport_title_format", messageData)
+		} else {
+			msgTitle = source.RenderMessage(configGameMessages, "news_updates_default_title", messageData)
+		}
+		msgBody := source.RenderMessage(configGameMessages, bodyKey, messageData)
+
+		// news are not urgent, so they could wait for the end of the quiet hours. They are not throttled because every story
+		// is distinct and their count is limited by the max pushes per check.
+		msg := notifications.Message{Topic: topic, Title: msgTitle, Body: msgBody, Data: data, Key: key, Locale: notificationConfig.GetTopicLocale(locale),
+			Unthrottled: true}
+		err := p.dispatcher.SendNotification(msg)
+		if err != nil {
+			log.Printf("sidearm -> sendNewsMessage: error sending notification topic:%s locale:%s title:%s body:%s data:%s %s", topic, locale, msgTitle, msgBody, data, err.Error())
+		} else {
+			log.Printf("sidearm -> sendNewsMessage: success sending notification topic:%s locale:%s title:%s body:%s data:%s", topic, locale, msgTitle, msgBody, data)
+		}
+	}
+}
+
+// getNewsHash gives the hash of the content of the story, so the edits could be detected
+func getNewsHash(news model.News) string {
+	return getHash(strings.Join([]string{news.Title, news.Description, news.FullTextRaw, news.ImageURL, news.Link}, "\n"))
+}
+
+func getHash(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:8])
+}
@@ -0,0 +1,485 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"sport/core/model"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	excerptLength  = 200
+	wordsPerMinute = 200
+)
+
+// allowedTags are the tags kept in the news content with their allowed attributes
+var allowedTags = map[atom.Atom][]string{
+	atom.P: nil, atom.Br: nil, atom.Hr: nil, atom.Div: nil, atom.Span: nil,
+	atom.B: nil, atom.Strong: nil, atom.I: nil, atom.Em: nil, atom.U: nil, atom.Sub: nil, atom.Sup: nil,
+	atom.H1: nil, atom.H2: nil, atom.H3: nil, atom.H4: nil, atom.H5: nil, atom.H6: nil,
+	atom.Ul: nil, atom.Ol: nil, atom.Li: nil, atom.Blockquote: nil, atom.Pre: nil, atom.Code: nil,
+	atom.Figure: nil, atom.Figcaption: nil,
+	atom.Table: nil, atom.Thead: nil, atom.Tbody: nil, atom.Tr: nil,
+	atom.Th: {"colspan", "rowspan"}, atom.Td: {"colspan", "rowspan"},
+	atom.A:   {"href", "title"},
+	atom.Img: {"src", "alt", "title", "width", "height"},
+}
+
+// droppedTags are removed from the news content together with their content, the other not allowed tags are replaced by their content
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Noscript: true,
+	atom.Form: true, atom.Input: true, atom.Button: true, atom.Textarea: true, atom.Select: true,
+	atom.Svg: true, atom.Math: true, atom.Head: true, atom.Title: true, atom.Meta: true, atom.Link: true,
+}
+
+// blockTags are separated by new lines in the plain text
+var blockTags = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.Div: true, atom.Li: true, atom.Blockquote: true, atom.Pre: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Figure: true, atom.Figcaption: true, atom.Table: true, atom.Tr: true,
+}
+
+// sanitizeNewsContent sanitizes the full text of the story and sets its excerpt and reading time
+func sanitizeNewsContent(news *model.News) {
+	news.FullText = sanitizeHTML(news.FullText)
+	news.FullTextRaw = sanitizeHTML(news.FullTextRaw)
+
+	text := htmlToText(news.FullText)
+	if len(text) == 0 {
+		text = htmlToText(sanitizeHTML(news.Description))
+	}
+	news.Excerpt = getExcerpt(text)
+	news.ReadingTimeMinutes = getReadingTime(text)
+}
+
+// formatNewsContent converts the sanitized full text of the stories to the format - html, text or markdown
+func formatNewsContent(news []model.News, format string) ([]model.News, error) {
+	var convert func(string) string
+	switch format {
+	case "", model.NewsFormatHTML:
+		return news, nil
+	case model.NewsFormatText:
+		convert = htmlToText
+	case model.NewsFormatMarkdown:
+		convert = htmlToMarkdown
+	default:
+		return nil, fmt.Errorf("not supported news format %s", format)
+	}
+
+	formatted := make([]model.News, len(news))
+	for i, item := range news {
+		item.FullText = convert(item.FullText)
+		item.FullTextRaw = convert(item.FullTextRaw)
+		formatted[i] = item
+	}
+	return formatted, nil
+}
+
+// sanitizeHTML keeps only the allowed tags and attributes and resolves the relative urls against the Sidearm host
+func sanitizeHTML(content string) string {
+	nodes := parseHTML(content)
+	if nodes == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, node := range nodes {
+		err := html.Render(&b, node)
+		if err != nil {
+			log.Printf("sidearm -> sanitizeHTML: failed to render html. Reason: %s", err.Error())
+			return ""
+		}
+	}
+	return b.String()
+}
+
+// parseHTML parses the html fragment and gives its sanitized nodes
+func parseHTML(content string) []*html.Node {
+	if len(strings.TrimSpace(content)) == 0 {
+		return nil
+	}
+	root := &html.Node{Type: html.ElementNode, DataAtom: atom.Div, Data: "div"}
+	nodes, err := html.ParseFragment(strings.NewReader(content), root)
+	if err != nil {
+		log.Printf("sidearm -> parseHTML: failed to parse html. Reason: %s", err.Error())
+		return nil
+	}
+	for _, node := range nodes {
+		root.AppendChild(node)
+	}
+	sanitizeChildren(root)
+
+	var sanitized []*html.Node
+	for node := root.FirstChild; node != nil; node = node.NextSibling {
+		sanitized = append(sanitized, node)
+	}
+	for _, node := range sanitized {
+		root.RemoveChild(node)
+	}
+	return sanitized
+}
+
+func sanitizeChildren(parent *html.Node) {
+	node := parent.FirstChild
+	for node != nil {
+		next := node.NextSibling
+		switch node.Type {
+		case html.TextNode:
+		case html.ElementNode:
+			attributes, allowed := allowedTags[node.DataAtom]
+			if droppedTags[node.DataAtom] || (allowed && !sanitizeAttributes(node, attributes)) {
+				parent.RemoveChild(node)
+			} else if !allowed {
+				//the content of the not allowed tag is kept in its place
+				first := node.FirstChild
+				for child := node.FirstChild; child != nil; child = node.FirstChild {
+					node.RemoveChild(child)
+					parent.InsertBefore(child, node)
+				}
+				parent.RemoveChild(node)
+				if first != nil {
+					next = first
+				}
+			} else {
+				sanitizeChildren(node)
+			}
+		default:
+			//comments, doctypes
+			parent.RemoveChild(node)
+		}
+		node = next
+	}
+}
+
+// sanitizeAttributes keeps the allowed attributes of the node. It gives false if the node should be removed, for example an image
+// without source or a tracking pixel.
+func sanitizeAttributes(node *html.Node, allowed []string) bool {
+	var attributes []html.Attribute
+	for _, attr := range node.Attr {
+		if len(attr.Namespace) > 0 || !containsString(allowed, strings.ToLower(attr.Key)) {
+			continue
+		}
+		key := strings.ToLower(attr.Key)
+		value := strings.TrimSpace(attr.Val)
+		if key == "href" || key == "src" {
+			value = sanitizeURL(value, node.DataAtom == atom.A)
+			if len(value) == 0 {
+				continue
+			}
+		}
+		attributes = append(attributes, html.Attribute{Key: key, Val: value})
+	}
+	node.Attr = attributes
+
+	switch node.DataAtom {
+	case atom.Img:
+		if len(getAttribute(node, "src")) == 0 || isTrackingPixel(node) {
+			return false
+		}
+	case atom.A:
+		if len(getAttribute(node, "href")) > 0 {
+			node.Attr = append(node.Attr, html.Attribute{Key: "rel", Val: "nofollow noopener noreferrer"})
+		}
+	}
+	return true
+}
+
+// sanitizeURL resolves the url against the Sidearm host. Only the http and https urls and the mailto links are allowed.
+func sanitizeURL(value string, link bool) string {
+	base, err := url.Parse(host + "/")
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+	resolved := base.ResolveReference(ref)
+	switch strings.ToLower(resolved.Scheme) {
+	case "http", "https":
+		return resolved.String()
+	case "mailto":
+		if link {
+			return resolved.String()
+		}
+	}
+	return ""
+}
+
+func isTrackingPixel(node *html.Node) bool {
+	for _, key := range []string{"width", "height"} {
+		size, err := strconv.Atoi(strings.TrimSuffix(getAttribute(node, key), "px"))
+		if err == nil && size <= 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func getAttribute(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlToText gives the plain text of the html, the blocks are separated by new lines
+func htmlToText(content string) string {
+	var b strings.Builder
+	for _, node := range parseHTML(content) {
+		writeText(&b, node)
+	}
+	return normalizeText(b.String())
+}
+
+func writeText(b *strings.Builder, node *html.Node) {
+	if node.Type == html.TextNode {
+		b.WriteString(node.Data)
+		return
+	}
+	block := blockTags[node.DataAtom]
+	if block {
+		b.WriteString("\n")
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeText(b, child)
+	}
+	if block {
+		b.WriteString("\n")
+	}
+}
+
+// normalizeText collapses the white spaces in the lines and removes the empty lines
+func normalizeText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// htmlToMarkdown converts the sanitized html to Markdown
+func htmlToMarkdown(content string) string {
+	var b strings.Builder
+	for _, node := range parseHTML(content) {
+		writeMarkdown(&b, node, "")
+	}
+
+	var lines []string
+	empty := true
+	for _, line := range strings.Split(b.String(), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if len(strings.TrimSpace(line)) == 0 {
+			if !empty {
+				lines = append(lines, "")
+			}
+			empty = true
+			continue
+		}
+		lines = append(lines, line)
+		empty = false
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func writeMarkdown(b *strings.Builder, node *html.Node, prefix string) {
+	if node.Type == html.TextNode {
+		b.WriteString(escapeMarkdown(collapseSpaces(node.Data)))
+		return
+	}
+
+	writeChildren := func(prefix string) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			writeMarkdown(b, child, prefix)
+		}
+	}
+	newLine := "\n" + prefix
+
+	switch node.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(node.Data[1] - '0')
+		b.WriteString(newLine + newLine + strings.Repeat("#", level) + " ")
+		writeChildren(prefix)
+		b.WriteString(newLine)
+	case atom.P, atom.Div, atom.Figure, atom.Table, atom.Ul, atom.Ol:
+		b.WriteString(newLine + newLine)
+		if node.DataAtom == atom.Ol {
+			writeOrderedList(b, node, prefix)
+		} else {
+			writeChildren(prefix)
+		}
+		b.WriteString(newLine)
+	case atom.Li:
+		b.WriteString(newLine + "- ")
+		writeChildren(prefix + "  ")
+	case atom.Tr, atom.Figcaption:
+		b.WriteString(newLine)
+		writeChildren(prefix)
+	case atom.Th, atom.Td:
+		writeChildren(prefix)
+		b.WriteString(" ")
+	case atom.Blockquote:
+		b.WriteString(newLine + newLine + "> ")
+		writeChildren(prefix + "> ")
+		b.WriteString(newLine)
+	case atom.Br:
+		b.WriteString("  " + newLine)
+	case atom.Hr:
+		b.WriteString(newLine + newLine + "---" + newLine)
+	case atom.B, atom.Strong:
+		writeWrapped(b, node, prefix, "**")
+	case atom.I, atom.Em:
+		writeWrapped(b, node, prefix, "_")
+	case atom.Code:
+		b.WriteString("`" + strings.ReplaceAll(textContent(node), "`", "") + "`")
+	case atom.Pre:
+		b.WriteString(newLine + newLine + "```" + newLine)
+		b.WriteString(strings.ReplaceAll(strings.TrimSpace(textContent(node)), "\n", newLine))
+		b.WriteString(newLine + "```" + newLine)
+	case atom.A:
+		href := getAttribute(node, "href")
+		if len(href) == 0 {
+			writeChildren(prefix)
+			return
+		}
+		b.WriteString("[")
+		writeChildren(prefix)
+		b.WriteString("](" + href + ")")
+	case atom.Img:
+		b.WriteString("![" + escapeMarkdown(getAttribute(node, "alt")) + "](" + getAttribute(node, "src") + ")")
+	default:
+		writeChildren(prefix)
+	}
+}
+
+func writeOrderedList(b *strings.Builder, node *html.Node, prefix string) {
+	number := 1
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom != atom.Li {
+			writeMarkdown(b, child, prefix)
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\n%s%d. ", prefix, number))
+		for c := child.FirstChild; c != nil; c = c.NextSibling {
+			writeMarkdown(b, c, prefix+"   ")
+		}
+		number++
+	}
+}
+
+func writeWrapped(b *strings.Builder, node *html.Node, prefix string, mark string) {
+	var inner strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeMarkdown(&inner, child, prefix)
+	}
+	text := inner.String()
+	trimmed := strings.TrimSpace(text)
+	if len(trimmed) == 0 {
+		b.WriteString(text)
+		return
+	}
+	//the marks must be next to the text
+	if text[0] == ' ' {
+		b.WriteString(" ")
+	}
+	b.WriteString(mark + trimmed + mark)
+	if text[len(text)-1] == ' ' {
+		b.WriteString(" ")
+	}
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+var markdownEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "[", "\\[", "]", "\\]", "#", "\\#")
+
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// collapseSpaces replaces the white spaces by one space
+func collapseSpaces(text string) string {
+	collapsed := strings.Join(strings.Fields(text), " ")
+	if len(text) == 0 {
+		return collapsed
+	}
+	if len(collapsed) == 0 {
+		return " "
+	}
+	if isSpace(text[0]) {
+		collapsed = " " + collapsed
+	}
+	if isSpace(text[len(text)-1]) {
+		collapsed += " "
+	}
+	return collapsed
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// getExcerpt gives the beginning of the text cut after a whole word
+func getExcerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= excerptLength {
+		return text
+	}
+	excerpt := string(runes[:excerptLength])
+	if i := strings.LastIndex(excerpt, " "); i > 0 {
+		excerpt = excerpt[:i]
+	}
+	return strings.TrimRight(excerpt, " ,.;:-") + "…"
+}
+
+// getReadingTime gives the estimated reading time of the text in minutes
+func getReadingTime(text string) int {
+	words := len(strings.Fields(text))
+	if words == 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(words) / wordsPerMinute))
+}
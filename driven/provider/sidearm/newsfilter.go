@@ -0,0 +1,159 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"sort"
+	"sport/core/model"
+	"strings"
+	"time"
+)
+
+const (
+	// the news are loaded from sidearm in growing steps until the page is covered
+	newsLoadStep = 100
+	maxNewsDepth = 1000
+	newsListTTL  = 5 * time.Minute
+)
+
+type cachedNewsList struct {
+	news     []model.News
+	complete bool // sidearm has no more news for the sports
+	loadedAt time.Time
+}
+
+// getNewsList gives at least depth latest news of the sports, or all of them if there are less. The lists are cached for a while.
+func (p *Provider) getNewsList(sports []string, depth int) (*cachedNewsList, error) {
+	keys := make([]string, len(sports))
+	for i, sport := range sports {
+		keys[i] = strings.ToLower(sport)
+	}
+	sort.Strings(keys)
+	key := strings.Join(keys, ",")
+
+	p.mu.Lock()
+	cached, exists := p.newsLists[key]
+	p.mu.Unlock()
+	if exists && time.Since(cached.loadedAt) < newsListTTL && (cached.complete || len(cached.news) >= depth) {
+		return &cached, nil
+	}
+
+	news, err := p.loadNews(nil, sports, depth)
+	if err != nil {
+		return nil, err
+	}
+	list := cachedNewsList{news: news, complete: len(news) < depth, loadedAt: time.Now()}
+	p.mu.Lock()
+	p.newsLists[key] = list
+	p.mu.Unlock()
+	return &list, nil
+}
+
+// filterNews gives the page of the news which match the filter. The news are ordered from the newest.
+func filterNews(news []model.News, filter model.NewsFilter) *model.NewsPage {
+	sorted := make([]model.News, len(news))
+	copy(sorted, news)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return isNewsBefore(sorted[i].PubDateUtc, sorted[i].ID, sorted[j].PubDateUtc, sorted[j].ID)
+	})
+
+	words := strings.Fields(strings.ToLower(filter.Query))
+	var matched []model.News
+	for _, item := range sorted {
+		if matchesNewsFilter(item, filter, words) {
+			matched = append(matched, item)
+		}
+	}
+
+	start := filter.Offset
+	if filter.Cursor != nil {
+		start = len(matched)
+		for i, item := range matched {
+			if isNewsBefore(filter.Cursor.PubDateUtc, filter.Cursor.ID, item.PubDateUtc, item.ID) {
+				start = i
+				break
+			}
+		}
+	}
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := len(matched)
+	if filter.Limit > 0 && start+filter.Limit < end {
+		end = start + filter.Limit
+	}
+
+	page := &model.NewsPage{News: matched[start:end], Total: len(matched)}
+	if end < len(matched) {
+		last := matched[end-1]
+		page.Next = &model.NewsCursor{PubDateUtc: last.PubDateUtc, ID: last.ID}
+	}
+	return page
+}
+
+func matchesNewsFilter(item model.News, filter model.NewsFilter, words []string) bool {
+	if len(filter.Sports) > 0 && !containsFold(filter.Sports, item.Sport) {
+		return false
+	}
+	if len(filter.Categories) > 0 && !containsFold(filter.Categories, item.Category) {
+		return false
+	}
+	if filter.Since != nil || filter.Until != nil {
+		pubDate, err := model.ParseNewsTime(item.PubDateUtc)
+		if err != nil {
+			return false
+		}
+		if filter.Since != nil && pubDate.Before(*filter.Since) {
+			return false
+		}
+		if filter.Until != nil && !pubDate.Before(*filter.Until) {
+			return false
+		}
+	}
+	if len(words) > 0 {
+		text := strings.ToLower(strings.Join([]string{item.Title, item.Description, item.FullText}, " "))
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isNewsBefore checks if the first news is before the second one in the list ordered from the newest
+func isNewsBefore(pubDate1 string, id1 string, pubDate2 string, id2 string) bool {
+	time1, err1 := model.ParseNewsTime(pubDate1)
+	time2, err2 := model.ParseNewsTime(pubDate2)
+	if err1 != nil {
+		time1 = time.Time{}
+	}
+	if err2 != nil {
+		time2 = time.Time{}
+	}
+	if !time1.Equal(time2) {
+		return time1.After(time2)
+	}
+	return id1 > id2
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
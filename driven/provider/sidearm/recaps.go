@@ -0,0 +1,145 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"fmt"
+	"log"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// notifyRecaps sends a follow-up notification for the final games once the recap story appears in the schedule.
+// If there is only a box score, it waits for the recap for a while before sending the box score.
+func (p *Provider) notifyRecaps(games []sidearmModel.Game, now time.Time) {
+	recapsConfig := p.config.NotificationConfig.Recaps
+	if !recapsConfig.Enabled {
+		return
+	}
+
+	p.mu.Lock()
+	current := make(map[string]bool, len(games))
+	for _, game := range games {
+		current[game.ID] = true
+	}
+	for gameID := range p.boxScoresSeen {
+		if !current[gameID] {
+			delete(p.boxScoresSeen, gameID)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, game := range games {
+		result := getFinalResult(game)
+		if result == nil {
+			continue
+		}
+		//do not send recaps for the old games when the service starts
+		start, err := time.Parse("2006-01-02T15:04:05Z", game.DateTimeUtc)
+		if err != nil || now.Sub(start) > time.Duration(recapsConfig.MaxAgeHours)*time.Hour {
+			continue
+		}
+
+		recapURL := getRecapURL(game)
+		boxScoreURL := getBoxScoreURL(game)
+		if len(recapURL) > 0 {
+			p.sendRecap(game, *result, "recap_msg", recapURL, boxScoreURL)
+		} else if len(boxScoreURL) > 0 {
+			p.mu.Lock()
+			seen, exists := p.boxScoresSeen[game.ID]
+			if !exists {
+				seen = now
+				p.boxScoresSeen[game.ID] = now
+			}
+			p.mu.Unlock()
+			if now.Sub(seen) >= time.Duration(recapsConfig.BoxScoreWaitMinutes)*time.Minute {
+				p.sendRecap(game, *result, "recap_boxscore_msg", recapURL, boxScoreURL)
+			}
+		}
+	}
+}
+
+func (p *Provider) sendRecap(game sidearmModel.Game, result sidearmModel.Result, bodyKey string, recapURL string, boxScoreURL string) {
+	messageData := p.scheduleMessageData(game)
+	messageData.OurScore, _ = strconv.Atoi(result.TeamScore)
+	messageData.OpponentScore, _ = strconv.Atoi(result.OpponentScore)
+	messageData.HomeScore, messageData.VisitingScore = messageData.OpponentScore, messageData.OurScore
+	if getHome(game) {
+		messageData.HomeScore, messageData.VisitingScore = messageData.OurScore, messageData.OpponentScore
+	}
+	switch result.Status {
+	case "W":
+		messageData.Result = "win"
+	case "L":
+		messageData.Result = "loss"
+	default:
+		messageData.Result = "tie"
+	}
+	if game.Links != nil && game.Links.PostGame != nil {
+		messageData.Title = game.Links.PostGame.Text
+	}
+
+	data := make(map[string]string)
+	if len(recapURL) > 0 {
+		data["recap_url"] = recapURL
+	}
+	if len(boxScoreURL) > 0 {
+		data["boxscore_url"] = boxScoreURL
+	}
+	//the recap is sent once, the box score only notification is not followed by the recap
+	key := fmt.Sprintf("recap.%s", game.ID)
+	log.Printf("sidearm -> sendRecap: game %s recap:%s boxscore:%s", game.ID, recapURL, boxScoreURL)
+	p.sendScheduleNotification(game, "recap", bodyKey, messageData, data, key, false)
+}
+
+// getFinalResult gives the result of the game if it is final
+func getFinalResult(game sidearmModel.Game) *sidearmModel.Result {
+	if game.Results == nil {
+		return nil
+	}
+	for _, result := range *game.Results {
+		if result.Status == "W" || result.Status == "L" || result.Status == "T" {
+			return &result
+		}
+	}
+	return nil
+}
+
+func getRecapURL(game sidearmModel.Game) string {
+	if game.Links == nil || game.Links.PostGame == nil {
+		return ""
+	}
+	if len(game.Links.PostGame.RedirectURL) > 0 {
+		return game.Links.PostGame.RedirectURL
+	}
+	return getAbsoluteURL(game.Links.PostGame.URL)
+}
+
+func getBoxScoreURL(game sidearmModel.Game) string {
+	if game.Links == nil || game.Links.BoxScore == nil {
+		return ""
+	}
+	return getAbsoluteURL(game.Links.BoxScore.URL)
+}
+
+// getAbsoluteURL resolves the Sidearm relative urls against the host
+func getAbsoluteURL(url string) string {
+	if strings.HasPrefix(url, "/") {
+		return host + url
+	}
+	return url
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"sport/driven/notifications"
+	"sport/driven/provider/sidearm/livestats/source"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"testing"
+	"time"
+)
+
+// finalGame gives a won game which started at the time with the links
+func finalGame(start time.Time, links *sidearmModel.Links) sidearmModel.Game {
+	return sidearmModel.Game{ID: "1001", DateTimeUtc: start.UTC().Format("2006-01-02T15:04:05Z"), Status: "A", Links: links,
+		Location: &sidearmModel.Location{HAN: "H"}, Opponent: &sidearmModel.Opponent{Name: "Iowa"}, Sport: &sidearmModel.Sport{ShortName: "football"},
+		Results: &[]sidearmModel.Result{{Status: "W", TeamScore: "21", OpponentScore: "14"}}, DateInfo: &sidearmModel.DateInfo{}}
+}
+
+func TestNotifyRecaps(t *testing.T) {
+	now := time.Date(2023, 10, 21, 22, 0, 0, 0, time.UTC)
+	recap := &sidearmModel.Links{PostGame: &sidearmModel.GameInfo{URL: "/news/2023/10/21/recap.aspx", Text: "Illini win"},
+		BoxScore: &sidearmModel.BoxScore{URL: "/boxscore.aspx?id=1001"}}
+	boxScore := &sidearmModel.Links{BoxScore: &sidearmModel.BoxScore{URL: "/boxscore.aspx?id=1001"}}
+	tests := []struct {
+		name     string
+		game     sidearmModel.Game
+		checks   []time.Duration // the times of the checks after now
+		body     string
+		recapURL string
+	}{
+		{"recap once", finalGame(now.Add(-3*time.Hour), recap), []time.Duration{0, time.Minute}, "Final: Illinois 21, Iowa 14. Read the recap: Illini win", host + "/news/2023/10/21/recap.aspx"},
+		{"box score waits for the recap", finalGame(now.Add(-3*time.Hour), boxScore), []time.Duration{0, time.Hour}, "", ""},
+		{"box score after the wait", finalGame(now.Add(-3*time.Hour), boxScore), []time.Duration{0, time.Hour, 2 * time.Hour}, "Final: Illinois 21, Iowa 14. The box score is available.", ""},
+		{"old game", finalGame(now.Add(-48*time.Hour), recap), []time.Duration{0}, "", ""},
+		{"game without result", sidearmModel.Game{ID: "1001", DateTimeUtc: now.Add(-3 * time.Hour).Format("2006-01-02T15:04:05Z"), Links: recap}, []time.Duration{0}, "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := source.NewConfig()
+			config.NotificationConfig.Locales = []string{"en"}
+			config.NotificationConfig.PerGameTopics = false
+			outbox := notifications.NewOutbox(notifications.NewRecorder(), nil, config.NotificationConfig.Outbox)
+			p := &Provider{config: config, teamName: "Illinois", dispatcher: notifications.NewDispatcher(outbox, nil, notifications.DispatcherConfig{}),
+				boxScoresSeen: make(map[string]time.Time)}
+			for _, check := range test.checks {
+				p.notifyRecaps([]sidearmModel.Game{test.game}, now.Add(check))
+			}
+
+			pending := outbox.GetOutbox().Pending
+			if len(test.body) == 0 {
+				if len(pending) > 0 {
+					t.Errorf("sent %+v, expected nothing", pending)
+				}
+				return
+			}
+			if len(pending) != 1 {
+				t.Fatalf("sent %+v, expected one recap", pending)
+			}
+			if pending[0].Body != test.body || pending[0].Data["recap_url"] != test.recapURL || pending[0].Data["boxscore_url"] != host+"/boxscore.aspx?id=1001" {
+				t.Errorf("sent %+v, expected %s", pending[0], test.body)
+			}
+		})
+	}
+}
+
+func TestBuildGamesResolvesTheLinks(t *testing.T) {
+	links := &sidearmModel.Links{Notes: "/notes.pdf", PostGame: &sidearmModel.GameInfo{URL: "/news/2023/10/21/recap.aspx"},
+		BoxScore: &sidearmModel.BoxScore{URL: "https://stats.example.com/boxscore.aspx"}}
+	games := buildGames(sidearmModel.Schedule{Games: []sidearmModel.Game{finalGame(time.Now(), links)}})
+	if len(games) != 1 {
+		t.Fatalf("built %d games, expected 1", len(games))
+	}
+	built := games[0].Links
+	if built.PostGame.URL != host+"/news/2023/10/21/recap.aspx" {
+		t.Errorf("recap url %s is not resolved against the host", built.PostGame.URL)
+	}
+	if built.Notes != host+"/notes.pdf" || built.BoxScore.URL != "https://stats.example.com/boxscore.aspx" {
+		t.Errorf("notes url %s and box score url %s", built.Notes, built.BoxScore.URL)
+	}
+}
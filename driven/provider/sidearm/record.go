@@ -0,0 +1,170 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strconv"
+	"strings"
+)
+
+var (
+	// for example "12-3", "12-3-1" or "12 - 3"
+	winLossRegexp = regexp.MustCompile(`^\s*(\d+)\s*-\s*(\d+)(?:\s*-\s*(\d+))?`)
+	// for example "W3", "L 2" or "Won 4"
+	streakRegexp = regexp.MustCompile(`(?i)^\s*(W|L|T)[a-z]*\s*(\d+)`)
+	// for example "1st", "T-2nd" or "T3rd"
+	rankRegexp = regexp.MustCompile(`(?i)\b(T-?)?(\d+)(?:st|nd|rd|th)\b`)
+)
+
+// buildRecord builds the record of the schedule. The raw strings are parsed, they are computed from the game results when
+// the record is missing.
+func buildRecord(sch sidearmModel.Schedule) model.Record {
+	r := sch.Record
+	if len(strings.TrimSpace(r.OverallRecord)) == 0 {
+		return computeRecord(sch.Games)
+	}
+
+	record := model.Record{OverallRecord: r.OverallRecord, ConferenceRecord: r.ConferenceRecord, Streak: r.Streak, HomeRecord: r.HomeRecord, AwayRecord: r.AwayRecord, NeutralRecord: r.NeutralRecord}
+	record.Overall = parseWinLoss(r.OverallRecord)
+	record.Conference = parseWinLoss(r.ConferenceRecord)
+	record.Home = parseWinLoss(r.HomeRecord)
+	record.Away = parseWinLoss(r.AwayRecord)
+	record.Neutral = parseWinLoss(r.NeutralRecord)
+	record.CurrentStreak = parseStreak(r.Streak)
+	record.ConferenceRank = parseRank(r.ConferenceRecord)
+	if record.ConferenceRank == nil {
+		record.ConferenceRank = parseRank(r.ConferencePoints)
+	}
+	return record
+}
+
+// computeRecord computes the record from the final games
+func computeRecord(games []sidearmModel.Game) model.Record {
+	var overall, conference, home, away, neutral model.WinLoss
+	var finals []sidearmModel.Game
+	for _, game := range games {
+		result := getFinalResult(game)
+		if result == nil {
+			continue
+		}
+		finals = append(finals, game)
+		addResult(&overall, result.Status)
+		if game.Type == "C" {
+			addResult(&conference, result.Status)
+		}
+		han := ""
+		if game.Location != nil {
+			han = game.Location.HAN
+		}
+		switch han {
+		case "H":
+			addResult(&home, result.Status)
+		case "A":
+			addResult(&away, result.Status)
+		case "N":
+			addResult(&neutral, result.Status)
+		}
+	}
+
+	record := model.Record{Computed: true}
+	if len(finals) == 0 {
+		return record
+	}
+	for _, winLoss := range []*model.WinLoss{&overall, &conference, &home, &away, &neutral} {
+		winLoss.Percentage = getWinPercentage(*winLoss)
+	}
+	record.Overall, record.OverallRecord = &overall, formatWinLoss(overall)
+	record.Home, record.HomeRecord = &home, formatWinLoss(home)
+	record.Away, record.AwayRecord = &away, formatWinLoss(away)
+	record.Neutral, record.NeutralRecord = &neutral, formatWinLoss(neutral)
+	if conference.Wins+conference.Losses+conference.Ties > 0 {
+		record.Conference, record.ConferenceRecord = &conference, formatWinLoss(conference)
+	}
+
+	//the streak starts with the most recent game
+	sort.SliceStable(finals, func(i, j int) bool {
+		return finals[i].DateTimeUtc > finals[j].DateTimeUtc
+	})
+	record.Streak = getStreak(finals)
+	record.CurrentStreak = parseStreak(record.Streak)
+	return record
+}
+
+func addResult(winLoss *model.WinLoss, status string) {
+	switch status {
+	case "W":
+		winLoss.Wins++
+	case "L":
+		winLoss.Losses++
+	default:
+		winLoss.Ties++
+	}
+}
+
+func parseWinLoss(value string) *model.WinLoss {
+	match := winLossRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return nil
+	}
+	var winLoss model.WinLoss
+	winLoss.Wins, _ = strconv.Atoi(match[1])
+	winLoss.Losses, _ = strconv.Atoi(match[2])
+	if len(match[3]) > 0 {
+		winLoss.Ties, _ = strconv.Atoi(match[3])
+	}
+	winLoss.Percentage = getWinPercentage(winLoss)
+	return &winLoss
+}
+
+func formatWinLoss(winLoss model.WinLoss) string {
+	if winLoss.Ties > 0 {
+		return fmt.Sprintf("%d-%d-%d", winLoss.Wins, winLoss.Losses, winLoss.Ties)
+	}
+	return fmt.Sprintf("%d-%d", winLoss.Wins, winLoss.Losses)
+}
+
+// getWinPercentage gives the win percentage rounded to three decimals, the ties count as half a win
+func getWinPercentage(winLoss model.WinLoss) float64 {
+	games := winLoss.Wins + winLoss.Losses + winLoss.Ties
+	if games == 0 {
+		return 0
+	}
+	percentage := (float64(winLoss.Wins) + float64(winLoss.Ties)/2) / float64(games)
+	return math.Round(percentage*1000) / 1000
+}
+
+func parseStreak(value string) *model.Streak {
+	match := streakRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return nil
+	}
+	length, _ := strconv.Atoi(match[2])
+	return &model.Streak{Type: strings.ToUpper(match[1]), Length: length}
+}
+
+func parseRank(value string) *model.Rank {
+	match := rankRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return nil
+	}
+	position, _ := strconv.Atoi(match[2])
+	return &model.Rank{Position: position, Tied: len(match[1]) > 0}
+}
@@ -0,0 +1,199 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sport/driven/notifications"
+	"sport/driven/provider/sidearm/livestats/source"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"time"
+)
+
+const (
+	// the reminders and the recaps are checked every minute
+	remindersInterval = time.Minute
+	// the reminders up to an hour before the game are sent also during the quiet hours
+	urgentReminderMinutes = 60
+)
+
+func (p *Provider) processReminders() {
+	for {
+		now := time.Now()
+		p.sendDueReminders(now)
+		//the recaps are checked here, so the box score wait ends on time and not with the next hourly schedule load
+		p.notifyRecaps(p.getCachedGames(), now)
+		timer := time.NewTimer(remindersInterval)
+
+		<-timer.C
+	}
+}
+
+// sendDueReminders sends the reminders which are due for the cached games. When more reminders of a game are due, for example
+// after a restart, only the closest to the game start is sent.
+func (p *Provider) sendDueReminders(now time.Time) {
+	remindersConfig := p.config.NotificationConfig.Reminders
+	if !remindersConfig.Enabled || len(remindersConfig.OffsetsMinutes) == 0 {
+		return
+	}
+	offsets := make([]int, len(remindersConfig.OffsetsMinutes))
+	copy(offsets, remindersConfig.OffsetsMinutes)
+	sort.Ints(offsets)
+
+	for _, game := range p.getCachedGames() {
+		start, ok := getScheduledStart(game)
+		if !ok || !start.After(now) {
+			continue
+		}
+		for _, offset := range offsets {
+			if offset > 0 && !now.Before(start.Add(-time.Duration(offset)*time.Minute)) {
+				p.sendReminder(game, start, offset)
+				break
+			}
+		}
+	}
+}
+
+func (p *Provider) sendReminder(game sidearmModel.Game, start time.Time, offset int) {
+	messageData := p.scheduleMessageData(game)
+	data := getGameLinksData(game)
+	key := fmt.Sprintf("reminder.%s.%d.%d", game.ID, start.Unix(), offset)
+	live := offset <= urgentReminderMinutes
+	log.Printf("sidearm -> sendReminder: game %s starts at %s, send %d minutes reminder", game.ID, start, offset)
+	p.sendScheduleNotification(game, "reminder", "reminder_msg", messageData, data, key, live)
+}
+
+// sendScheduleNotification sends notification about a scheduled game to the "athletics.{sport}.notification.{kind}" topic and
+// to the per-game topic in every configured locale
+func (p *Provider) sendScheduleNotification(game sidearmModel.Game, kind string, bodyKey string, messageData source.MessageData,
+	data map[string]string, key string, live bool) {
+	if game.Sport == nil {
+		return
+	}
+	notificationConfig := p.config.NotificationConfig
+	sport := game.Sport.ShortName
+	topics := []string{fmt.Sprintf("athletics.%s.notification.%s", sport, kind)}
+	if notificationConfig.PerGameTopics {
+		topics = append(topics, fmt.Sprintf("athletics.%s.game.%s.notification.%s", sport, game.ID, kind))
+	}
+	data["GameId"] = game.ID
+	data["Path"] = sport
+	data["click_action"] = "FLUTTER_NOTIFICATION_CLICK"
+
+	for _, locale := range notificationConfig.GetLocales() {
+		messages := notificationConfig.GetMessages(locale)
+		title := source.RenderMessage(messages, "game_title_format", messageData)
+		body := source.RenderMessage(messages, bodyKey, messageData)
+		for i, topic := range topics {
+			msgKey := key
+			if i > 0 {
+				msgKey = fmt.Sprintf("%s.game", key)
+			}
+			msg := notifications.Message{Topic: topic, Title: title, Body: body, Data: data, Key: msgKey, Live: live, Locale: notificationConfig.GetTopicLocale(locale)}
+			err := p.dispatcher.SendNotification(msg)
+			if err != nil {
+				log.Printf("sidearm -> sendScheduleNotification: error sending notification topic:%s locale:%s title:%s body:%s data:%s %s", topic, locale, title, body, data, err.Error())
+			} else {
+				log.Printf("sidearm -> sendScheduleNotification: success sending notification topic:%s locale:%s title:%s body:%s data:%s", topic, locale, title, body, data)
+			}
+		}
+	}
+}
+
+// getCachedGames gives a copy of the cached games
+func (p *Provider) getCachedGames() []sidearmModel.Game {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	games := make([]sidearmModel.Game, len(p.cachedGames))
+	copy(games, p.cachedGames)
+	return games
+}
+
+// scheduleMessageData gives the fields of a scheduled game for the message templates
+func (p *Provider) scheduleMessageData(game sidearmModel.Game) source.MessageData {
+	opponent := getOpponentName(game)
+	data := source.MessageData{HomeTeam: opponent, VisitingTeam: p.teamName, Opponent: opponent, OurTeam: p.teamName, Result: "tie"}
+	if getHome(game) {
+		data.HomeTeam, data.VisitingTeam = p.teamName, opponent
+	}
+	if game.Sport != nil {
+		data.Sport = game.Sport.ShortName
+	}
+	if game.Location != nil {
+		data.Location = game.Location.Location
+	}
+	if start, ok := getScheduledStart(game); ok {
+		data.StartTime = formatChicagoTime(start)
+	}
+	return data
+}
+
+// getScheduledStart gives the start of the game if it is scheduled for a known time
+func getScheduledStart(game sidearmModel.Game) (time.Time, bool) {
+	if getScheduleStatus(game) != "scheduled" {
+		return time.Time{}, false
+	}
+	if game.DateInfo != nil && (game.DateInfo.Tbd || game.DateInfo.AllDay) {
+		return time.Time{}, false
+	}
+	if game.DateTimeUtc == "" {
+		return time.Time{}, false
+	}
+	start, err := time.Parse("2006-01-02T15:04:05Z", game.DateTimeUtc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// getGameLinksData gives the ticket, parking, TV and radio links of the game for the notification data
+func getGameLinksData(game sidearmModel.Game) map[string]string {
+	data := make(map[string]string)
+	if game.Links != nil {
+		if len(game.Links.Tickets) > 0 {
+			data["tickets_url"] = game.Links.Tickets
+		}
+		if len(game.Links.Audio) > 0 {
+			data["radio_url"] = game.Links.Audio
+		}
+		if len(game.Links.Video) > 0 {
+			data["video_url"] = game.Links.Video
+		}
+	}
+	if parkingURL := getParkingURL(game.DisplayField2); parkingURL != nil {
+		data["parking_url"] = *parkingURL
+	}
+	if len(game.TV) > 0 {
+		data["tv"] = game.TV
+	}
+	if len(game.Radio) > 0 {
+		data["radio"] = game.Radio
+	}
+	return data
+}
+
+// formatChicagoTime formats the time in the Chicago time zone, for example "Sat, Oct 21 2:30 PM"
+func formatChicagoTime(t time.Time) string {
+	location, err := time.LoadLocation("America/Chicago")
+	if err == nil {
+		t = t.In(location)
+	} else {
+		log.Printf("sidearm -> formatChicagoTime: failed to load Chicago location. Reason: %s", err.Error())
+	}
+	return t.Format("Mon, Jan 2 3:04 PM")
+}
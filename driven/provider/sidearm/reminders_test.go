@@ -0,0 +1,42 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"testing"
+)
+
+func TestScheduleMessageDataUsesTheTeamName(t *testing.T) {
+	p := &Provider{teamName: "Chicago"}
+	tests := []struct {
+		name     string
+		han      string
+		home     string
+		visiting string
+	}{
+		{"home game", "H", "Chicago", "Iowa"},
+		{"away game", "A", "Iowa", "Chicago"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := sidearmModel.Game{Location: &sidearmModel.Location{HAN: test.han, Location: "Champaign, Ill."}, Opponent: &sidearmModel.Opponent{Name: "Iowa"}}
+			data := p.scheduleMessageData(game)
+			if data.HomeTeam != test.home || data.VisitingTeam != test.visiting || data.OurTeam != "Chicago" || data.Opponent != "Iowa" {
+				t.Errorf("data %+v, expected %s vs %s", data, test.home, test.visiting)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	playersEndpoint string = "/services/roster_xml.aspx?format=json"
+	coachesEndpoint string = "/services/coaches_xml.aspx?format=json"
+	// the rosters of the past seasons rarely change
+	pastRosterTTL = 24 * time.Hour
+)
+
+type cachedRoster struct {
+	roster   *sidearmModel.Rosters
+	loadedAt time.Time
+}
+
+// GetPlayer retrieves the full profile of the player, it gives nil if there is no such player in the roster
+func (p *Provider) GetPlayer(sport string, year *int, id string) (*model.Player, error) {
+	players, err := p.GetPlayers(sport, year, model.PlayersFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, player := range players {
+		if player.ID == id {
+			return &player, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetCoach retrieves the full profile of the coach, it gives nil if there is no such coach in the roster
+func (p *Provider) GetCoach(sport string, year *int, id string) (*model.Coach, error) {
+	coaches, err := p.GetCoaches(sport, year)
+	if err != nil {
+		return nil, err
+	}
+	for _, coach := range coaches {
+		if coach.ID == id {
+			return &coach, nil
+		}
+	}
+	return nil, nil
+}
+
+// loadSeasonRoster loads the roster of the season which is resolved like the team schedule season. The current roster is loaded
+// if year is nil. The rosters of the past seasons are cached.
+func (p *Provider) loadSeasonRoster(endpoint string, sport string, year *int) (*sidearmModel.Rosters, error) {
+	var path string
+	if sport != "" {
+		path = fmt.Sprintf("&path=%s", sport)
+	}
+	if year == nil {
+		return loadRoster(host + endpoint + path)
+	}
+
+	season, err := getSportSeason(sport, year)
+	if err != nil {
+		return nil, err
+	}
+	if season == nil {
+		return nil, fmt.Errorf("sidearm -> loadSeasonRoster: season %d was not found", *year)
+	}
+
+	var url string
+	if endpoint == playersEndpoint && len(season.RosterURL) > 0 {
+		url = season.RosterURL
+	} else if season.RosterID != nil {
+		url = host + endpoint + path + fmt.Sprintf("&roster_id=%d", *season.RosterID)
+	} else {
+		return nil, fmt.Errorf("sidearm -> loadSeasonRoster: there is no roster for season [%s]", season.Year)
+	}
+
+	past := !season.Current && !isCurrentYear(season.Year)
+	if past {
+		p.mu.Lock()
+		cached, exists := p.pastRosters[url]
+		p.mu.Unlock()
+		if exists && time.Since(cached.loadedAt) < pastRosterTTL {
+			return cached.roster, nil
+		}
+	}
+
+	roster, err := loadRoster(url)
+	if err != nil {
+		return nil, err
+	}
+	if past {
+		p.mu.Lock()
+		p.pastRosters[url] = cachedRoster{roster: roster, loadedAt: time.Now()}
+		p.mu.Unlock()
+	}
+	return roster, nil
+}
+
+func loadRoster(url string) (*sidearmModel.Rosters, error) {
+	bodyBytes, err := request(http.MethodGet, url, nil)
+	if err != nil {
+		log.Printf("sidearm -> loadRoster: Failed to request roster. Reason: %s", err.Error())
+		return nil, err
+	}
+
+	var r sidearmModel.Rosters
+	err = json.Unmarshal(bodyBytes, &r)
+	if err != nil {
+		log.Printf("sidearm -> loadRoster: Failed to unmarshal response json. Reason: %s", err.Error())
+		return nil, err
+	}
+	return &r, nil
+}
+
+func buildCoach(r sidearmModel.Roster) model.Coach {
+	photos := buildRosterPhotos(r.Photos)
+	return model.Coach{ID: strconv.Itoa(r.StaffID), Name: r.Name, FirstName: r.FirstName, LastName: r.LastName, Email: r.StaffInfo.Email, Phone: r.StaffInfo.Phone,
+		Title: r.StaffInfo.Title, Bio: r.Bio, BioLink: getAbsoluteURL(r.StaffInfo.BioLink), Photos: photos}
+}
+
+func buildPlayer(r sidearmModel.Roster) model.Player {
+	info := r.PlayerInfo
+	player := model.Player{ID: r.PlayerID, Name: r.Name, FirstName: r.FirstName, LastName: r.LastName, Uni: info.Uni, PosShort: info.PosShort, Height: info.Height,
+		Wight: info.Weight, Gender: info.Gender, YearLong: info.YearLong, HomeTown: info.HomeTown, HighSchool: info.HighSchool, Captain: info.Captain == "True", Bio: r.Bio,
+		Uni2: info.Uni2, PosLong: info.PosLong, Positions: info.PosShortList, Year: info.Year, Major: info.Major, PreviousSchool: info.PrevSchool,
+		BioLink: getAbsoluteURL(info.BioLink), ShopURL: info.ShopURL}
+	player.HeightFeet, _ = strconv.Atoi(strings.TrimSpace(info.HeightFeet))
+	player.HeightInches, _ = strconv.Atoi(strings.TrimSpace(info.HeightInches))
+	if photos := buildRosterPhotos(r.Photos); photos != nil {
+		player.Photos = photos
+	}
+
+	social := model.PlayerSocial{Facebook: info.FbUsrName, Instagram: info.InstUsrName, Twitter: info.TwitUsrName, Snapchat: info.SnapUsrName,
+		TikTok: info.TikTokUsrName, Cameo: info.CameoUsrName, YouTube: info.YouTubeUsrName, Twitch: info.TwitchUsrName}
+	if social != (model.PlayerSocial{}) {
+		player.Social = &social
+	}
+	return player
+}
+
+// filterPlayers gives the players which match the filter in its order
+func filterPlayers(players []model.Player, filter model.PlayersFilter) []model.Player {
+	var filtered []model.Player
+	for _, player := range players {
+		if len(filter.Position) > 0 && !hasPosition(player, filter.Position) {
+			continue
+		}
+		if len(filter.Class) > 0 && !strings.EqualFold(player.Year, filter.Class) && !strings.EqualFold(player.YearLong, filter.Class) {
+			continue
+		}
+		if len(filter.Number) > 0 && player.Uni != filter.Number && player.Uni2 != filter.Number {
+			continue
+		}
+		filtered = append(filtered, player)
+	}
+
+	var less func(p1 model.Player, p2 model.Player) bool
+	switch filter.Sort {
+	case model.PlayersSortName:
+		less = func(p1 model.Player, p2 model.Player) bool {
+			if !strings.EqualFold(p1.LastName, p2.LastName) {
+				return strings.ToLower(p1.LastName) < strings.ToLower(p2.LastName)
+			}
+			return strings.ToLower(p1.FirstName) < strings.ToLower(p2.FirstName)
+		}
+	case model.PlayersSortNumber:
+		less = isNumberBefore
+	case model.PlayersSortPosition:
+		less = func(p1 model.Player, p2 model.Player) bool {
+			if p1.PosShort != p2.PosShort {
+				return p1.PosShort < p2.PosShort
+			}
+			return isNumberBefore(p1, p2)
+		}
+	case model.PlayersSortClass:
+		less = func(p1 model.Player, p2 model.Player) bool {
+			c1, c2 := getClassOrder(p1), getClassOrder(p2)
+			if c1 != c2 {
+				return c1 < c2
+			}
+			return isNumberBefore(p1, p2)
+		}
+	}
+	if less != nil {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return less(filtered[i], filtered[j])
+		})
+	}
+	return filtered
+}
+
+func hasPosition(player model.Player, position string) bool {
+	if strings.EqualFold(player.PosShort, position) {
+		return true
+	}
+	for _, pos := range player.Positions {
+		if strings.EqualFold(pos, position) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumberBefore compares the jersey numbers, the players without number are the last
+func isNumberBefore(p1 model.Player, p2 model.Player) bool {
+	n1, err1 := strconv.Atoi(p1.Uni)
+	n2, err2 := strconv.Atoi(p2.Uni)
+	switch {
+	case err1 == nil && err2 == nil:
+		if n1 != n2 {
+			return n1 < n2
+		}
+		//"0" and "00"
+		return len(p1.Uni) < len(p2.Uni)
+	case err1 == nil:
+		return true
+	case err2 == nil:
+		return false
+	default:
+		return p1.Uni < p2.Uni
+	}
+}
+
+// getClassOrder gives the order of the class year - freshmen first, then sophomores, juniors, seniors and graduates
+func getClassOrder(player model.Player) int {
+	class := strings.ToLower(player.Year + " " + player.YearLong)
+	for i, prefix := range []string{"fr", "so", "jr", "sr", "gr"} {
+		if strings.Contains(class, prefix) {
+			return i
+		}
+	}
+	switch {
+	case strings.Contains(class, "junior"):
+		return 2
+	case strings.Contains(class, "senior"):
+		return 3
+	}
+	return 5
+}
+
+// isCurrentYear checks if the season year, for example "2022" or "2022-23", could be still in progress
+func isCurrentYear(year string) bool {
+	now := time.Now().Year()
+	return len(year) >= 4 && (year[:4] == fmt.Sprint(now) || year[:4] == fmt.Sprint(now-1))
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"fmt"
+	"log"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"strings"
+	"time"
+)
+
+const scheduleState string = "schedule_snapshot"
+
+// scheduleChange is a change of a game between two schedule snapshots
+type scheduleChange struct {
+	kind  string // time_changed, tbd_resolved, rescheduled, location_changed, postponed or cancelled
+	value string // the new value, a change is sent once for it
+}
+
+// loadScheduleSnapshot loads the last schedule, so the changes while the service was not running are detected too
+func (p *Provider) loadScheduleSnapshot() []sidearmModel.Game {
+	var games []sidearmModel.Game
+	if p.storage == nil {
+		return games
+	}
+	err := p.storage.LoadState(scheduleState, &games)
+	if err != nil {
+		log.Printf("sidearm -> loadScheduleSnapshot: failed to load schedule snapshot. Reason: %s", err.Error())
+	}
+	return games
+}
+
+func (p *Provider) saveScheduleSnapshot(games []sidearmModel.Game) {
+	if p.storage == nil {
+		return
+	}
+	err := p.storage.SaveState(scheduleState, games)
+	if err != nil {
+		log.Printf("sidearm -> saveScheduleSnapshot: failed to save schedule snapshot. Reason: %s", err.Error())
+	}
+}
+
+// notifyScheduleChanges compares the new schedule with the previous one and notifies the subscribers of the sport for the changes
+func (p *Provider) notifyScheduleChanges(previous []sidearmModel.Game, current []sidearmModel.Game) {
+	previousGames := make(map[string]sidearmModel.Game, len(previous))
+	for _, game := range previous {
+		previousGames[game.ID] = game
+	}
+
+	for _, game := range current {
+		//the games which disappear from the schedule are the past ones, so only the games in both snapshots are compared
+		previousGame, exists := previousGames[game.ID]
+		if !exists {
+			continue
+		}
+		for _, change := range detectScheduleChanges(previousGame, game) {
+			p.sendScheduleChange(previousGame, game, change)
+		}
+	}
+}
+
+func (p *Provider) sendScheduleChange(previous sidearmModel.Game, game sidearmModel.Game, change scheduleChange) {
+	messageData := p.scheduleMessageData(game)
+	previousData := p.scheduleMessageData(previous)
+	messageData.PreviousStartTime = previousData.StartTime
+	messageData.PreviousLocation = previousData.Location
+	messageData.Reason = strings.TrimSpace(game.NoPlayText)
+
+	data := getGameLinksData(game)
+	data["change"] = change.kind
+	key := fmt.Sprintf("schedule.%s.%s.%s", game.ID, change.kind, change.value)
+
+	//the changes of the games in the next day are urgent
+	start, ok := getScheduledStart(previous)
+	live := ok && time.Until(start) < 24*time.Hour
+
+	log.Printf("sidearm -> sendScheduleChange: game %s %s", game.ID, change.kind)
+	p.sendScheduleNotification(game, "schedule", fmt.Sprintf("schedule_%s_msg", change.kind), messageData, data, key, live)
+}
+
+// detectScheduleChanges gives the changes of the game between two schedule snapshots
+func detectScheduleChanges(previous sidearmModel.Game, game sidearmModel.Game) []scheduleChange {
+	var changes []scheduleChange
+
+	previousStatus := getScheduleStatus(previous)
+	status := getScheduleStatus(game)
+	if status != previousStatus && (status == "cancelled" || status == "postponed") {
+		changes = append(changes, scheduleChange{kind: status, value: game.DateTimeUtc})
+		return changes
+	}
+
+	previousStart, previousOk := getScheduledStart(previous)
+	start, ok := getScheduledStart(game)
+	if ok {
+		if previousStatus == "postponed" {
+			changes = append(changes, scheduleChange{kind: "rescheduled", value: game.DateTimeUtc})
+		} else if previous.DateInfo != nil && previous.DateInfo.Tbd {
+			changes = append(changes, scheduleChange{kind: "tbd_resolved", value: game.DateTimeUtc})
+		} else if previousOk && !start.Equal(previousStart) {
+			changes = append(changes, scheduleChange{kind: "time_changed", value: game.DateTimeUtc})
+		}
+	}
+
+	previousLocation := getLocationName(previous)
+	location := getLocationName(game)
+	if len(previousLocation) > 0 && len(location) > 0 && location != previousLocation {
+		changes = append(changes, scheduleChange{kind: "location_changed", value: location})
+	}
+	return changes
+}
+
+// getScheduleStatus gives cancelled, postponed or scheduled based on the status and the no play text of the game
+func getScheduleStatus(game sidearmModel.Game) string {
+	noPlayText := strings.ToLower(game.NoPlayText)
+	switch {
+	case game.Status == "C" || strings.Contains(noPlayText, "cancel"):
+		return "cancelled"
+	case game.Status == "P" || strings.Contains(noPlayText, "postpone"):
+		return "postponed"
+	default:
+		return "scheduled"
+	}
+}
+
+func getLocationName(game sidearmModel.Game) string {
+	if game.Location == nil {
+		return ""
+	}
+	return strings.TrimSpace(game.Location.Location)
+}
@@ -21,11 +21,13 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"reflect"
 	"sport/core/model"
 	"sport/driven/notifications"
 	"sport/driven/provider/sidearm/livestats"
 	"sport/driven/provider/sidearm/livestats/source"
 	sidearmModel "sport/driven/provider/sidearm/model"
+	"sport/driven/standings"
 	"strconv"
 	"strings"
 	"sync"
@@ -35,139 +37,137 @@ import (
 const host string = "https://fightingillini.com"
 const illinoisTeamName string = "Illinois"
 
+// the sports assets rarely change, so they are loaded again only after this time
+const sportsAssetsTTL = time.Hour
+
 // Provider implements Provider interface
 type Provider struct {
-	mu            sync.Mutex
-	stats         livestats.LiveStats
-	config        source.Config
-	notifications notifications.Notifications
-	nextGame      sidearmModel.LiveGameItem
-	startedGames  []*sidearmModel.LiveGameItem
-	cachedGames   []sidearmModel.Game
-	cachedNews    []model.News
-}
-
-// NewProvider creates new provider instance
-func NewProvider(internalAPIKey string, host string, ftpHost string, ftpUser string, ftpPassword string, appID string, orgID string) *Provider {
+	mu           sync.Mutex
+	stats        livestats.LiveStats
+	config       source.Config
+	rokwire      notifications.Notifier
+	teamName     string
+	storage      notifications.Storage
+	dispatcher   *notifications.Dispatcher
+	outbox       *notifications.Outbox
+	standings    standings.Source
+	nextGame     sidearmModel.LiveGameItem
+	startedGames []*sidearmModel.LiveGameItem
+	cachedGames  []sidearmModel.Game
+	cachedNews   []model.News
+	// the time when the box score of a game without recap was first seen
+	boxScoresSeen map[string]time.Time
+	newsSeen      map[string]newsRecord
+	startedAt     time.Time
+	// the schedules of the seasons by the schedule url
+	schedules   map[string]cachedSchedule
+	pastRosters map[string]cachedRoster
+	// the news lists by the sports for the news API
+	newsLists map[string]cachedNewsList
+	// the social networks and the conferences of the sports
+	sportsAssets         *sidearmModel.SportsSocNet
+	sportsAssetsLoadedAt time.Time
+}
+
+// NewProvider creates new provider instance. The rokwire notifier delivers the messages unless the config selects another transport.
+func NewProvider(rokwire notifications.Notifier, ftpHost string, ftpUser string, ftpPassword string, storage notifications.Storage) *Provider {
 	config := source.NewConfig()
-	notifications := notifications.New(internalAPIKey, host, appID, orgID)
-	stats := livestats.New(notifications, config, ftpHost, ftpUser, ftpPassword, illinoisTeamName)
-	return &Provider{stats: stats, config: config, notifications: notifications}
+	notifier := notifications.NewNotifier(config.NotificationConfig.Notifier, rokwire)
+	outbox := notifications.NewOutbox(notifier, storage, config.NotificationConfig.Outbox)
+	dispatcher := notifications.NewDispatcher(outbox, storage, config.NotificationConfig.Dispatcher)
+	teamName := illinoisTeamName
+	stats := livestats.New(dispatcher, config, ftpHost, ftpUser, ftpPassword, teamName, storage)
+	return &Provider{stats: stats, config: config, rokwire: rokwire, teamName: teamName, storage: storage, dispatcher: dispatcher, outbox: outbox, standings: standings.NewSource(config.StandingsConfig),
+		boxScoresSeen: make(map[string]time.Time), startedAt: time.Now(), schedules: make(map[string]cachedSchedule),
+		pastRosters: make(map[string]cachedRoster), newsLists: make(map[string]cachedNewsList)}
 }
 
 // Start Provider
 func (p *Provider) Start() {
+	p.newsSeen = p.loadNewsSeen()
+	p.outbox.Start()
 	go p.processCachedGames()
 	go p.processLiveStats()
+	go p.processReminders()
 	p.loadCachedNews()
 	go p.processCachedNews()
 }
 
-// GetNews retrieves the news from sidearm service
-func (p *Provider) GetNews(id *string, sports []string, limit int) ([]model.News, error) {
-	return p.loadNews(id, sports, limit)
-}
-
-// GetCoaches retrieves the coaches from sidearm service
-func (p *Provider) GetCoaches(sport string) ([]model.Coach, error) {
-	coachesEndpoint := "/services/coaches_xml.aspx?format=json"
-
-	if sport != "" {
-		coachesEndpoint += fmt.Sprintf("&path=%s", sport)
+// GetNews retrieves the news from sidearm service. The news with id is loaded from sidearm, the lists are filtered from the cached news.
+func (p *Provider) GetNews(filter model.NewsFilter) (*model.NewsPage, error) {
+	if filter.ID != nil {
+		news, err := p.loadNews(filter.ID, filter.Sports, filter.Limit)
+		if err != nil {
+			return nil, err
+		}
+		news, err = formatNewsContent(news, filter.Format)
+		if err != nil {
+			return nil, err
+		}
+		return &model.NewsPage{News: news, Total: len(news), TotalExact: true}, nil
 	}
 
-	url := host + coachesEndpoint
-	bodyBytes, err := request(http.MethodGet, url, nil)
-
+	//load more news while the page could continue after the loaded ones
+	depth := newsLoadStep
+	var page *model.NewsPage
+	for {
+		list, err := p.getNewsList(filter.Sports, depth)
+		if err != nil {
+			return nil, err
+		}
+		page = filterNews(list.news, filter)
+		page.TotalExact = list.complete
+		if list.complete || page.Next != nil || depth >= maxNewsDepth {
+			break
+		}
+		depth *= 2
+		if depth > maxNewsDepth {
+			depth = maxNewsDepth
+		}
+	}
+	news, err := formatNewsContent(page.News, filter.Format)
 	if err != nil {
-		errMsg := fmt.Sprintf("sidearm -> GetCoaches: Failed to request coaches. Reason: %s", err.Error())
-		log.Print(errMsg)
 		return nil, err
 	}
+	page.News = news
+	return page, nil
+}
 
-	var r sidearmModel.Rosters
-	em := json.Unmarshal(bodyBytes, &r)
-	if em != nil {
-		log.Printf("sidearm -> GetCoaches: Failed to unmarshal response json. Reason: %s", em.Error())
-		return nil, em
+// GetCoaches retrieves the coaches of the season from sidearm service, the current coaches if year is nil
+func (p *Provider) GetCoaches(sport string, year *int) ([]model.Coach, error) {
+	r, err := p.loadSeasonRoster(coachesEndpoint, sport, year)
+	if err != nil {
+		return nil, err
 	}
+
 	var coaches []model.Coach
-	rosters := r.Rosters
-	if (rosters != nil) && (len(rosters) > 0) {
-		for i := 0; i < len(rosters); i++ {
-			r := rosters[i]
-			photos := buildRosterPhotos(r.Photos)
-			coaches = append(coaches, model.Coach{ID: strconv.Itoa(r.StaffID), Name: r.Name, FirstName: r.FirstName, LastName: r.LastName, Email: r.StaffInfo.Email, Phone: r.StaffInfo.Phone, Title: r.StaffInfo.Title, Bio: r.Bio, Photos: photos})
-		}
+	for _, roster := range r.Rosters {
+		coaches = append(coaches, buildCoach(roster))
 	}
-
 	return coaches, nil
 }
 
-// GetPlayers retrieves the players from sidearm service
-func (p *Provider) GetPlayers(sport string) ([]model.Player, error) {
-	rosterEndpoint := "/services/roster_xml.aspx?format=json"
-
-	if sport != "" {
-		rosterEndpoint += fmt.Sprintf("&path=%s", sport)
-	}
-
-	url := host + rosterEndpoint
-	bodyBytes, err := request(http.MethodGet, url, nil)
-
+// GetPlayers retrieves the players of the season from sidearm service, the current roster if year is nil
+func (p *Provider) GetPlayers(sport string, year *int, filter model.PlayersFilter) ([]model.Player, error) {
+	r, err := p.loadSeasonRoster(playersEndpoint, sport, year)
 	if err != nil {
-		errMsg := fmt.Sprintf("sidearm -> GetPlayers: Failed to request players. Reason: %s", err.Error())
-		log.Print(errMsg)
 		return nil, err
 	}
 
-	var r sidearmModel.Rosters
-	em := json.Unmarshal(bodyBytes, &r)
-	if em != nil {
-		log.Printf("sidearm -> GetPlayers: Failed to unmarshal response json. Reason: %s", em.Error())
-		return nil, em
-	}
 	var players []model.Player
-	rosters := r.Rosters
-	if (rosters != nil) && (len(rosters) > 0) {
-		for i := 0; i < len(rosters); i++ {
-			r := rosters[i]
-			photos := buildRosterPhotos(r.Photos)
-			var captain bool
-			if r.PlayerInfo.Captain == "True" {
-				captain = true
-			} else {
-				captain = false
-			}
-			player := model.Player{ID: r.PlayerID, Name: r.Name, FirstName: r.FirstName, LastName: r.LastName, Uni: r.PlayerInfo.Uni, PosShort: r.PlayerInfo.PosShort, Height: r.PlayerInfo.Height, Wight: r.PlayerInfo.Weight, Gender: r.PlayerInfo.Gender, YearLong: r.PlayerInfo.YearLong, HomeTown: r.PlayerInfo.HomeTown, HighSchool: r.PlayerInfo.HighSchool, Captain: captain, Bio: r.Bio}
-			if photos != nil {
-				player.Photos = photos
-			}
-			players = append(players, player)
-		}
+	for _, roster := range r.Rosters {
+		players = append(players, buildPlayer(roster))
 	}
-
-	return players, nil
+	return filterPlayers(players, filter), nil
 }
 
 // GetSocialNetworks retrieves social accounts from sidearm service
 func (p *Provider) GetSocialNetworks() ([]model.SportSocial, error) {
-	url := host + "/api/assets?operation=sports"
-	bodyBytes, err := request(http.MethodGet, url, nil)
-
+	s, err := p.getSportsAssets()
 	if err != nil {
-		errMsg := fmt.Sprintf("sidearm -> GetSocialNetworks: Failed to request social networks. Reason: %s", err.Error())
-		log.Print(errMsg)
 		return nil, err
 	}
 
-	var s sidearmModel.SportsSocNet
-	es := json.Unmarshal(bodyBytes, &s)
-	if es != nil {
-		log.Printf("sidearm -> GetSocialNetworks: Failed to unmarshal response json. Reason: %s", es.Error())
-		return nil, es
-	}
-
 	var socNetList []model.SportSocial
 	srcSocNet := s.SportsSocial
 	if (srcSocNet != nil) && (len(srcSocNet) > 0) {
@@ -181,33 +181,74 @@ func (p *Provider) GetSocialNetworks() ([]model.SportSocial, error) {
 	return socNetList, nil
 }
 
+// getSportsAssets gives the cached sports assets, it loads them again once they are older than sportsAssetsTTL
+func (p *Provider) getSportsAssets() (*sidearmModel.SportsSocNet, error) {
+	p.mu.Lock()
+	assets := p.sportsAssets
+	loadedAt := p.sportsAssetsLoadedAt
+	p.mu.Unlock()
+	if assets != nil && time.Since(loadedAt) < sportsAssetsTTL {
+		return assets, nil
+	}
+
+	assets, err := loadSportsAssets()
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.sportsAssets = assets
+	p.sportsAssetsLoadedAt = time.Now()
+	p.mu.Unlock()
+	return assets, nil
+}
+
+// loadSportsAssets loads the social networks and the conferences of the sports
+func loadSportsAssets() (*sidearmModel.SportsSocNet, error) {
+	url := host + "/api/assets?operation=sports"
+	bodyBytes, err := request(http.MethodGet, url, nil)
+
+	if err != nil {
+		errMsg := fmt.Sprintf("sidearm -> loadSportsAssets: Failed to request sports assets. Reason: %s", err.Error())
+		log.Print(errMsg)
+		return nil, err
+	}
+
+	var s sidearmModel.SportsSocNet
+	es := json.Unmarshal(bodyBytes, &s)
+	if es != nil {
+		log.Printf("sidearm -> loadSportsAssets: Failed to unmarshal response json. Reason: %s", es.Error())
+		return nil, es
+	}
+	return &s, nil
+}
+
 // GetGames retrieves games from sidearm
-func (p *Provider) GetGames(sports []string, id *string, startDate *string, endDate *string, limit int) ([]model.Game, error) {
+func (p *Provider) GetGames(filter model.GamesFilter) ([]model.Game, error) {
 	gamesEndpoint := "/services/schedule_xml_2.aspx?format=json"
 
-	if len(sports) > 0 {
-		for i := 0; i < len(sports); i++ {
-			gamesEndpoint += "&path=" + sports[i]
+	if len(filter.Sports) > 0 {
+		for i := 0; i < len(filter.Sports); i++ {
+			gamesEndpoint += "&path=" + filter.Sports[i]
 		}
 	}
 
-	if id != nil {
-		gamesEndpoint += "&game_id=" + *id
+	if filter.ID != nil {
+		gamesEndpoint += "&game_id=" + *filter.ID
 	}
 
-	if limit > 0 {
-		gamesEndpoint += "&take=" + strconv.Itoa(limit)
+	if filter.Limit > 0 {
+		gamesEndpoint += "&take=" + strconv.Itoa(filter.Limit)
 	}
 
-	if startDate == nil {
-		chd := getChicagoTime()
-		startDate = &chd
+	//sidearm filters by the Chicago dates, the times are filtered after that
+	if filter.Start != nil {
+		gamesEndpoint += "&starting=" + formatChicagoDate(*filter.Start)
+	} else if !filter.Past {
+		gamesEndpoint += "&starting=" + getChicagoTime()
 	}
 
-	gamesEndpoint += "&starting=" + *startDate
-
-	if endDate != nil {
-		gamesEndpoint += "&ending=" + *endDate
+	if filter.End != nil {
+		gamesEndpoint += "&ending=" + formatChicagoDate(filter.End.Add(-time.Nanosecond))
 	}
 
 	url := host + gamesEndpoint
@@ -226,10 +267,30 @@ func (p *Provider) GetGames(sports []string, id *string, startDate *string, endD
 		return nil, es
 	}
 
-	games := buildGames(s)
+	games := filterGamesByTime(buildGames(s), filter.Start, filter.End)
 	return games, nil
 }
 
+// filterGamesByTime skips the games which start out of the range. The games without start time are kept.
+func filterGamesByTime(games []model.Game, start *time.Time, end *time.Time) []model.Game {
+	if start == nil && end == nil {
+		return games
+	}
+	var filtered []model.Game
+	for _, game := range games {
+		if game.StartTime != nil {
+			if start != nil && game.StartTime.Before(*start) {
+				continue
+			}
+			if end != nil && !game.StartTime.Before(*end) {
+				continue
+			}
+		}
+		filtered = append(filtered, game)
+	}
+	return filtered
+}
+
 // GetTeamSchedule retrieves team schedule for specific year
 func (p *Provider) GetTeamSchedule(sport string, year *int) (*model.Schedule, error) {
 	s, err := getSportSeason(sport, year)
@@ -266,9 +327,8 @@ func (p *Provider) GetTeamRecord(sport string, year *int) (*model.Record, error)
 		return nil, err
 	}
 
-	r := sch.Record
-
-	return &model.Record{OverallRecord: r.OverallRecord, ConferenceRecord: r.ConferenceRecord, Streak: r.Streak, HomeRecord: r.HomeRecord, AwayRecord: r.AwayRecord, NeutralRecord: r.NeutralRecord}, nil
+	record := buildRecord(*sch)
+	return &record, nil
 }
 
 // GetLiveGames retrieves current live games
@@ -276,6 +336,11 @@ func (p *Provider) GetLiveGames() ([]model.LiveGame, error) {
 	return p.stats.LiveData(), nil
 }
 
+// GetPlays retrieves the plays of the game from the live stats after the since sequence number, it gives nil if there are no plays for the game
+func (p *Provider) GetPlays(gameID int, since int) (*model.GamePlays, error) {
+	return p.stats.Plays(gameID, since), nil
+}
+
 // GetConfig retrieves the config
 func (p *Provider) GetConfig() (map[string]interface{}, error) {
 	cfgBytes, err := json.Marshal(p.config)
@@ -308,40 +373,102 @@ func (p *Provider) UpdateConfig(cfgBytes []byte) error {
 		return err
 	}
 
+	cfg.NotificationConfig.TranslateLegacyMessages()
+	err = cfg.NotificationConfig.Validate()
+	if err != nil {
+		log.Printf("sidearm -> UpdateConfig: invalid notification config. Reason: %s", err.Error())
+		return err
+	}
+
+	previousNotifier := p.config.NotificationConfig.Notifier
+	previousStandings := p.config.StandingsConfig
 	p.config = cfg
 	p.stats.UpdateConfig(cfg)
+	p.dispatcher.UpdateConfig(cfg.NotificationConfig.Dispatcher)
+	p.outbox.UpdateConfig(cfg.NotificationConfig.Outbox)
+	if !reflect.DeepEqual(previousNotifier, cfg.NotificationConfig.Notifier) {
+		p.outbox.SetNotifier(notifications.NewNotifier(cfg.NotificationConfig.Notifier, p.rokwire))
+	}
+	if previousStandings != cfg.StandingsConfig {
+		p.mu.Lock()
+		p.standings = standings.NewSource(cfg.StandingsConfig)
+		p.mu.Unlock()
+	}
 	return nil
 }
 
-func getSportSeason(sport string, year *int) (*sidearmModel.Season, error) {
-	seasonsEndpoint := "/services/schedule_xml_2.aspx?format=json&sportseasons=true"
+// GetNotificationsOutbox retrieves the pending and the dead-lettered notifications
+func (p *Provider) GetNotificationsOutbox() (*model.NotificationsOutbox, error) {
+	outbox := p.outbox.GetOutbox()
+	return &outbox, nil
+}
 
-	seasonsEndpoint += "&path=" + sport
+// GetSourceHealthEvents retrieves the latest health events of the live stats sources, the oldest first
+func (p *Provider) GetSourceHealthEvents() ([]model.SourceHealthEvent, error) {
+	events := []model.SourceHealthEvent{}
+	for _, event := range p.stats.HealthEvents() {
+		events = append(events, model.SourceHealthEvent{GameID: event.GameID, Sport: event.Sport, Source: event.Source,
+			Type: event.Type, Message: event.Message, Time: event.Time})
+	}
+	return events, nil
+}
 
-	if year != nil {
-		seasonsEndpoint += "&year=" + strconv.Itoa(*year)
+// ReplayNotifications sends again the dead-lettered notification with the given id or all of them if id is nil
+func (p *Provider) ReplayNotifications(id *string) (int, error) {
+	return p.outbox.Replay(id), nil
+}
+
+// PreviewNotification renders a message template against the sample data or a game
+func (p *Provider) PreviewNotification(preview model.NotificationPreview) (string, error) {
+	notificationConfig := p.config.NotificationConfig
+	locale := preview.Locale
+	if len(locale) == 0 {
+		locale = notificationConfig.DefaultLocale
 	}
+	messages := notificationConfig.GetMessages(locale)
 
-	seasonsURL := host + seasonsEndpoint
-	seasonsBodyBytes, err := request(http.MethodGet, seasonsURL, nil)
-	if err != nil {
-		errMsg := fmt.Sprintf("sidearm -> getSportSeason: Failed to load sport seasons games. Reason: %s", err.Error())
-		log.Print(errMsg)
-		return nil, err
+	text := preview.Template
+	if len(text) == 0 {
+		message, exists := messages[preview.Key]
+		if !exists {
+			return "", fmt.Errorf("unknown message key %s", preview.Key)
+		}
+		text = message
 	}
 
-	var ss sidearmModel.SportSeasons
-	err = json.Unmarshal(seasonsBodyBytes, &ss)
+	data := source.SampleMessageData()
+	if len(preview.GameID) > 0 {
+		var game *sidearmModel.Game
+		p.mu.Lock()
+		for i := range p.cachedGames {
+			if p.cachedGames[i].ID == preview.GameID {
+				game = &p.cachedGames[i]
+				break
+			}
+		}
+		p.mu.Unlock()
+		if game == nil {
+			return "", fmt.Errorf("game %s not found", preview.GameID)
+		}
+		item := sidearmModel.LiveGameItem{GameID: game.ID, Home: getHome(*game), OpponentName: getOpponentName(*game), Status: game.Status}
+		if game.Sport != nil {
+			item.Sport = game.Sport.ShortName
+		}
+		data = p.stats.MessageData(&item)
+	}
+	data.Score = source.RenderMessage(messages, source.ScoreMessageKey, data)
+	data.Phase = source.RenderPeriodLabel(messages, data)
+
+	return source.RenderTemplate(text, data)
+}
+
+func getSportSeason(sport string, year *int) (*sidearmModel.Season, error) {
+	ss, err := getSportSeasons(sport, year)
 	if err != nil {
-		log.Printf("sidearm -> getSportSeason: Failed to unmarshal response json. Reason: %s", err.Error())
 		return nil, err
 	}
 
 	l := len(ss.Seasons)
-	if l == 0 {
-		return nil, fmt.Errorf("sidearm -> getSportSeason: there are no seasons for sport [%s]", sport)
-	}
-
 	var s *sidearmModel.Season
 	if year != nil {
 		// Get season for specific year
@@ -360,6 +487,36 @@ func getSportSeason(sport string, year *int) (*sidearmModel.Season, error) {
 	return s, nil
 }
 
+func getSportSeasons(sport string, year *int) (*sidearmModel.SportSeasons, error) {
+	seasonsEndpoint := "/services/schedule_xml_2.aspx?format=json&sportseasons=true"
+
+	seasonsEndpoint += "&path=" + sport
+
+	if year != nil {
+		seasonsEndpoint += "&year=" + strconv.Itoa(*year)
+	}
+
+	seasonsURL := host + seasonsEndpoint
+	seasonsBodyBytes, err := request(http.MethodGet, seasonsURL, nil)
+	if err != nil {
+		errMsg := fmt.Sprintf("sidearm -> getSportSeasons: Failed to load sport seasons games. Reason: %s", err.Error())
+		log.Print(errMsg)
+		return nil, err
+	}
+
+	var ss sidearmModel.SportSeasons
+	err = json.Unmarshal(seasonsBodyBytes, &ss)
+	if err != nil {
+		log.Printf("sidearm -> getSportSeasons: Failed to unmarshal response json. Reason: %s", err.Error())
+		return nil, err
+	}
+
+	if len(ss.Seasons) == 0 {
+		return nil, fmt.Errorf("sidearm -> getSportSeasons: there are no seasons for sport [%s]", sport)
+	}
+	return &ss, nil
+}
+
 func getSchedule(s sidearmModel.Season) (*sidearmModel.Schedule, error) {
 	scheduleURL := s.ScheduleURL
 	if len(scheduleURL) == 0 {
@@ -400,17 +557,32 @@ func buildGames(s sidearmModel.Schedule) []model.Game {
 
 			var links model.Links
 			if s.Links != nil {
-				links = model.Links{Livestats: s.Links.Livestats, Video: s.Links.Video, Audio: s.Links.Audio, Tickets: s.Links.Tickets}
+				links = model.Links{Livestats: s.Links.Livestats, Video: s.Links.Video, Audio: s.Links.Audio, Tickets: s.Links.Tickets, Notes: getAbsoluteURL(s.Links.Notes)}
 				var preGame model.GameInfo
 				if s.Links.PreGame != nil {
 					preGame = model.GameInfo{ID: s.Links.PreGame.ID, URL: s.Links.PreGame.URL, StoryImageURL: s.Links.PreGame.StoryImageURL, Text: s.Links.PreGame.Text}
 					links.PreGame = &preGame
 				}
+				var postGame model.GameInfo
+				if s.Links.PostGame != nil {
+					postGame = model.GameInfo{ID: s.Links.PostGame.ID, URL: getAbsoluteURL(s.Links.PostGame.URL), StoryImageURL: s.Links.PostGame.StoryImageURL, Text: s.Links.PostGame.Text}
+					links.PostGame = &postGame
+				}
+				var boxScore model.BoxScore
+				if s.Links.BoxScore != nil {
+					boxScore = model.BoxScore{URL: getAbsoluteURL(s.Links.BoxScore.URL), Text: s.Links.BoxScore.Text}
+					links.BoxScore = &boxScore
+				}
+				if s.Links.GameFiles != nil {
+					for _, gameFile := range *s.Links.GameFiles {
+						links.GameFiles = append(links.GameFiles, model.GameFile{Link: getAbsoluteURL(gameFile.Link), Title: gameFile.Title})
+					}
+				}
 			}
 
 			var opponent model.Opponent
 			if s.Opponent != nil {
-				opponent = model.Opponent{Name: s.Opponent.Name, LogoImage: s.Opponent.LogoImage}
+				opponent = model.Opponent{GlobalID: s.Opponent.GlobalID, Name: s.Opponent.Name, LogoImage: s.Opponent.LogoImage}
 			}
 
 			var results []model.Result
@@ -422,7 +594,8 @@ func buildGames(s sidearmModel.Schedule) []model.Game {
 			}
 			parkingURL := getParkingURL(s.DisplayField2)
 			name := getName(s)
-			games = append(games, model.Game{ID: s.ID, Name: name, Date: s.Date, DateTimeUtc: s.DateTimeUtc, EndDateTimeUtc: s.EndDateTimeUtc, EndDate: s.EndDateTime, Time: s.Time, AllDay: s.DateInfo.AllDay, Status: s.Status, Description: s.PromotionName, Sport: &sport, Location: &location, ParkingURL: parkingURL, Links: &links, Opponent: &opponent, Results: &results})
+			games = append(games, model.Game{ID: s.ID, Name: name, Date: s.Date, DateTimeUtc: s.DateTimeUtc, EndDateTimeUtc: s.EndDateTimeUtc, EndDate: s.EndDateTime, Time: s.Time, AllDay: s.DateInfo.AllDay, Status: s.Status, Description: s.PromotionName, Sport: &sport, Location: &location, ParkingURL: parkingURL, Links: &links, Opponent: &opponent, Results: &results,
+				StartTime: parseUtcTime(s.DateTimeUtc), EndTime: parseUtcTime(s.EndDateTimeUtc)})
 		}
 	}
 	return games
@@ -457,6 +630,30 @@ func getParkingURL(displayField2 string) *string {
 	return &url
 }
 
+// parseUtcTime parses the sidearm UTC time, it gives nil if the time is not set or not valid
+func parseUtcTime(value string) *time.Time {
+	if len(value) == 0 {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// formatChicagoDate formats the date in the Chicago time zone as sidearm expects it - MM/dd/yyyy
+func formatChicagoDate(t time.Time) string {
+	tl, err := time.LoadLocation("America/Chicago")
+	if err == nil {
+		t = t.In(tl)
+	} else {
+		log.Printf("sidearm -> formatChicagoDate: failed to load Chicago location -> error:\n%s", err.Error())
+	}
+	return t.Format("01/02/2006")
+}
+
 func getChicagoTime() string {
 	now := time.Now()
 	tl, err := time.LoadLocation("America/Chicago")
@@ -567,9 +764,16 @@ func (p *Provider) loadCachedGames() {
 	}
 
 	p.mu.Lock()
+	previous := p.cachedGames
 	p.cachedGames = schedule.Games
 	p.mu.Unlock()
 	log.Println("sidearm -> loadCachedGames: games loaded")
+
+	if previous == nil {
+		previous = p.loadScheduleSnapshot()
+	}
+	p.notifyScheduleChanges(previous, schedule.Games)
+	p.saveScheduleSnapshot(schedule.Games)
 }
 
 func (p *Provider) processNextGameItems() {
@@ -602,8 +806,9 @@ func (p *Provider) processNextGameItems() {
 						next.Sport = game.Sport.ShortName
 						next.Home = home
 						next.OpponentName = opponentName
+						next.Status = game.Status
 					} else {
-						started = append(started, &sidearmModel.LiveGameItem{GameID: gameID, Time: t, Sport: game.Sport.ShortName, Home: home, OpponentName: opponentName})
+						started = append(started, &sidearmModel.LiveGameItem{GameID: gameID, Time: t, Sport: game.Sport.ShortName, Home: home, OpponentName: opponentName, Status: game.Status})
 					}
 				} else {
 					log.Printf("sidearm -> processNextGameItems: failed to parse datetime_utc string to time. Reason: %s", err.Error())
@@ -738,7 +943,9 @@ func (p *Provider) loadNews(id *string, sports []string, limit int) ([]model.New
 			if e != nil {
 				imageURL = e.URL
 			}
-			news = append(news, model.News{ID: s.ID, Title: s.Title, Sport: sport.PrimaryGlobalShortName, Link: s.Link, Category: s.Category, Description: s.Description, FullText: s.FullText, FullTextRaw: s.FullTextRaw, ImageURL: imageURL, PubDateUtc: s.PubDateUtc})
+			item := model.News{ID: s.ID, Title: s.Title, Sport: sport.PrimaryGlobalShortName, Link: s.Link, Category: s.Category, Description: s.Description, FullText: s.FullText, FullTextRaw: s.FullTextRaw, ImageURL: imageURL, PubDateUtc: s.PubDateUtc}
+			sanitizeNewsContent(&item)
+			news = append(news, item)
 		}
 	}
 
@@ -779,62 +986,17 @@ func (p *Provider) checkUpdatedNews() {
 		return
 	}
 
-	if len(p.cachedNews) == 0 {
-		log.Println("sidearm -> checkUpdatedNews: No cached news.")
-		return
-	}
-
-	for i := 0; i < len(curNews); i++ {
-		item := curNews[i]
-		exists := p.isNewsCached(item)
-		if !exists {
-			log.Printf("sidearm -> checkUpdatedNews: Found new item: %s", item.ID)
-			p.sendNewsNotification(item)
-		}
-	}
+	created, edited := p.detectNewsChanges(curNews, time.Now())
+	p.notifyNews(created, edited)
 
 	p.mu.Lock()
 	p.cachedNews = curNews
-	p.mu.Unlock()
-}
-
-func (p *Provider) sendNewsNotification(news model.News) {
-	configGameMessages := p.config.NotificationConfig.Messages
-	category := news.Category
-	var msgTitle string
-	if len(category) > 0 {
-		titleFmt := configGameMessages["news_updates_sport_title_format"]
-		msgTitle = fmt.Sprintf(titleFmt, category)
-	} else {
-		msgTitle = configGameMessages["news_updates_default_title"]
+	newsSeen := make(map[string]newsRecord, len(p.newsSeen))
+	for id, record := range p.newsSeen {
+		newsSeen[id] = record
 	}
-
-	msgBodyFormat := configGameMessages["news_updates_body_content_format"]
-	msgBody := fmt.Sprintf(msgBodyFormat, news.Title)
-
-	// topic is "athletics.{sport_short_name}.notification.news"
-	topic := fmt.Sprintf("athletics.%s.notification.news", news.Sport)
-
-	data := make((map[string]string))
-	data["type"] = "athletics_news_detail"
-	data["sport"] = news.Sport
-	data["news_id"] = news.ID
-	data["click_action"] = "FLUTTER_NOTIFICATION_CLICK"
-	err := p.notifications.SendNotificationMsg(topic, msgTitle, msgBody, data)
-	if err != nil {
-		log.Printf("sidearm -> sendNewsNotification: error sending notification topic:%s title:%s body:%s data:%s %s", topic, msgTitle, msgBody, data, err.Error())
-	} else {
-		log.Printf("sidearm -> sendNewsNotification: success sending notification topic:%s title:%s body:%s data:%s", topic, msgTitle, msgBody, data)
-	}
-}
-
-func (p *Provider) isNewsCached(item model.News) bool {
-	for _, b := range p.cachedNews {
-		if b.ID == item.ID {
-			return true
-		}
-	}
-	return false
+	p.mu.Unlock()
+	p.saveNewsSeen(newsSeen)
 }
 
 func hasData(item sidearmModel.LiveGameItem) bool {
@@ -0,0 +1,90 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidearm
+
+import (
+	"log"
+	"sort"
+	"sport/core/model"
+	sidearmModel "sport/driven/provider/sidearm/model"
+	"sport/driven/standings"
+	"strings"
+)
+
+// GetStandings retrieves the conference standings of the sport. The conference of the sport comes from the Sidearm sports assets.
+// It gives nil if there is no standings source configured.
+func (p *Provider) GetStandings(sport string) (*model.Standings, error) {
+	conference := p.getSportConference(sport)
+	var conferenceID string
+	if conference != nil {
+		conferenceID = conference.ConfID
+	}
+
+	p.mu.Lock()
+	source := p.standings
+	p.mu.Unlock()
+	sportStandings, err := source.LoadStandings(sport, conferenceID)
+	if err == standings.ErrNotConfigured {
+		log.Printf("sidearm -> GetStandings: no standings for %s. Reason: %s", sport, err.Error())
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("sidearm -> GetStandings: failed to load %s standings. Reason: %s", sport, err.Error())
+		return nil, err
+	}
+
+	result := model.Standings{Sport: sport, ConferenceID: sportStandings.ConferenceID, ConferenceTitle: sportStandings.ConferenceTitle,
+		Division: sportStandings.Division, Teams: []model.StandingsTeam{}}
+	if conference != nil {
+		if len(result.ConferenceID) == 0 {
+			result.ConferenceID = conference.ConfID
+		}
+		if len(result.ConferenceTitle) == 0 {
+			result.ConferenceTitle = conference.ConfTitle
+		}
+		if len(result.Division) == 0 {
+			result.Division = conference.ConfDevision
+		}
+	}
+	if !sportStandings.UpdatedAt.IsZero() {
+		updatedAt := sportStandings.UpdatedAt
+		result.UpdatedAt = &updatedAt
+	}
+
+	for _, team := range sportStandings.Teams {
+		result.Teams = append(result.Teams, model.StandingsTeam{Rank: model.Rank{Position: team.Rank, Tied: team.Tied}, Name: team.Name,
+			GlobalID: team.GlobalID, Division: team.Division, ConferenceRecord: team.ConferenceRecord, Conference: parseWinLoss(team.ConferenceRecord),
+			OverallRecord: team.OverallRecord, Overall: parseWinLoss(team.OverallRecord), Streak: team.Streak, CurrentStreak: parseStreak(team.Streak),
+			OurTeam: strings.EqualFold(strings.TrimSpace(team.Name), p.teamName)})
+	}
+	sort.SliceStable(result.Teams, func(i, j int) bool {
+		return result.Teams[i].Rank.Position < result.Teams[j].Rank.Position
+	})
+	return &result, nil
+}
+
+// getSportConference gives the conference of the sport, it gives nil if the sports assets cannot be loaded
+func (p *Provider) getSportConference(sport string) *sidearmModel.SocNets {
+	assets, err := p.getSportsAssets()
+	if err != nil {
+		return nil
+	}
+	for _, item := range assets.SportsSocial {
+		if item.ShortName == sport && len(item.ConfID) > 0 {
+			return &item
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"time"
+)
+
+// The standings sources
+const (
+	SourceNone string = "none"
+	SourceFile string = "file"
+)
+
+// ErrNotConfigured is returned when there is no standings source
+var ErrNotConfigured = errors.New("standings source is not configured")
+
+// Config structure
+type Config struct {
+	Source string `json:"source"` // none or file
+	File   string `json:"file"`   // the path of the standings file for the file source
+}
+
+// Standings are the conference standings of a sport as the source gives them
+type Standings struct {
+	ConferenceID    string    `json:"conference_id"`
+	ConferenceTitle string    `json:"conference_title"`
+	Division        string    `json:"division"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Teams           []Team    `json:"teams"`
+}
+
+// Team is a row of the standings
+type Team struct {
+	Rank             int    `json:"rank"`
+	Tied             bool   `json:"tied"`
+	Name             string `json:"name"`
+	GlobalID         int    `json:"global_id"`
+	Division         string `json:"division"`
+	ConferenceRecord string `json:"conference_record"`
+	OverallRecord    string `json:"overall_record"`
+	Streak           string `json:"streak"`
+}
+
+// Source loads the conference standings
+type Source interface {
+	LoadStandings(sport string, conferenceID string) (*Standings, error)
+}
+
+// NewSource creates the standings source of the config
+func NewSource(config Config) Source {
+	switch config.Source {
+	case SourceFile:
+		return &fileSource{path: config.File}
+	default:
+		return noneSource{}
+	}
+}
+
+type noneSource struct{}
+
+func (noneSource) LoadStandings(sport string, conferenceID string) (*Standings, error) {
+	return nil, ErrNotConfigured
+}
+
+// fileSource reads the standings from a JSON file with the standings by sport, it is used for testing
+type fileSource struct {
+	path string
+}
+
+func (s *fileSource) LoadStandings(sport string, conferenceID string) (*Standings, error) {
+	fileBytes, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		log.Printf("standings -> LoadStandings: failed to read %s. Reason: %s", s.path, err.Error())
+		return nil, err
+	}
+
+	var standings map[string]Standings
+	err = json.Unmarshal(fileBytes, &standings)
+	if err != nil {
+		log.Printf("standings -> LoadStandings: failed to unmarshal %s. Reason: %s", s.path, err.Error())
+		return nil, err
+	}
+
+	sportStandings, exists := standings[sport]
+	if !exists {
+		return nil, fmt.Errorf("there are no standings for sport [%s]", sport)
+	}
+	if len(conferenceID) > 0 && len(sportStandings.ConferenceID) > 0 && conferenceID != sportStandings.ConferenceID {
+		return nil, fmt.Errorf("the standings of sport [%s] are for conference [%s], not [%s]", sport, sportStandings.ConferenceID, conferenceID)
+	}
+	return &sportStandings, nil
+}
@@ -15,12 +15,19 @@
 package storage
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
+	"sync"
 )
 
+const stateDir string = "driven/storage/data"
+
 // Adapter implements Storage interface
 type Adapter struct {
+	mu sync.Mutex
 }
 
 // GetSports retrieves sport definitions
@@ -33,6 +40,60 @@ func (sa *Adapter) GetSports() string {
 	return string(fileBytes)
 }
 
+// LoadState loads the persisted state with the given name into value. It does nothing if the state does not exist.
+func (sa *Adapter) LoadState(name string, value interface{}) error {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	fileBytes, err := ioutil.ReadFile(statePath(name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("storage -> LoadState: failed to read %s state. Reason: %s", name, err.Error())
+		return err
+	}
+	err = json.Unmarshal(fileBytes, value)
+	if err != nil {
+		log.Printf("storage -> LoadState: failed to unmarshal %s state. Reason: %s", name, err.Error())
+		return err
+	}
+	return nil
+}
+
+// SaveState persists the value as the state with the given name
+func (sa *Adapter) SaveState(name string, value interface{}) error {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	fileBytes, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("storage -> SaveState: failed to marshal %s state. Reason: %s", name, err.Error())
+		return err
+	}
+	err = os.MkdirAll(stateDir, 0755)
+	if err != nil {
+		log.Printf("storage -> SaveState: failed to create state directory. Reason: %s", err.Error())
+		return err
+	}
+
+	// write to a temporary file first so the state is never left half written
+	path := statePath(name)
+	err = ioutil.WriteFile(path+".tmp", fileBytes, 0644)
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		log.Printf("storage -> SaveState: failed to write %s state. Reason: %s", name, err.Error())
+		return err
+	}
+	return nil
+}
+
+func statePath(name string) string {
+	return filepath.Join(stateDir, name+".json")
+}
+
 // NewStorageAdapter creates new instance
 func NewStorageAdapter() *Adapter {
 	return &Adapter{}
@@ -46,15 +46,30 @@ func (we Adapter) Start() {
 	v2SubRouter := apiSubRouter.PathPrefix("/v2").Subrouter()
 	v2SubRouter.HandleFunc("/config", we.corePermissionWrapFunc(we.apis.GetConfig)).Methods("GET")
 	v2SubRouter.HandleFunc("/config", we.corePermissionWrapFunc(we.apis.UpdateConfig)).Methods("PUT")
+	v2SubRouter.HandleFunc("/admin/notifications/outbox", we.corePermissionWrapFunc(we.apis.GetNotificationsOutbox)).Methods("GET")
+	v2SubRouter.HandleFunc("/admin/notifications/preview", we.corePermissionWrapFunc(we.apis.PreviewNotification)).Methods("POST")
+	v2SubRouter.HandleFunc("/admin/notifications/outbox/replay", we.corePermissionWrapFunc(we.apis.ReplayNotifications)).Methods("POST")
+	v2SubRouter.HandleFunc("/admin/livestats/health", we.corePermissionWrapFunc(we.apis.GetSourceHealthEvents)).Methods("GET")
 	v2SubRouter.HandleFunc("/sports", we.coreWrapFunc(we.apis.GetSports)).Methods("GET")
 	v2SubRouter.HandleFunc("/news", we.coreWrapFunc(we.apis.GetNews)).Methods("GET")
 	v2SubRouter.HandleFunc("/coaches", we.coreWrapFunc(we.apis.GetCoaches)).Methods("GET")
 	v2SubRouter.HandleFunc("/players", we.coreWrapFunc(we.apis.GetPlayers)).Methods("GET")
+	v2SubRouter.HandleFunc("/players/{id}", we.coreWrapFunc(we.apis.GetPlayer)).Methods("GET")
+	v2SubRouter.HandleFunc("/coaches/{id}", we.coreWrapFunc(we.apis.GetCoach)).Methods("GET")
 	v2SubRouter.HandleFunc("/social", we.coreWrapFunc(we.apis.GetSocialNetworks)).Methods("GET")
 	v2SubRouter.HandleFunc("/games", we.coreWrapFunc(we.apis.GetGames)).Methods("GET")
 	v2SubRouter.HandleFunc("/team-schedule", we.coreWrapFunc(we.apis.GetTeamSchedule)).Methods("GET")
 	v2SubRouter.HandleFunc("/team-record", we.coreWrapFunc(we.apis.GetTeamRecord)).Methods("GET")
+	v2SubRouter.HandleFunc("/team-history", we.coreWrapFunc(we.apis.GetTeamHistory)).Methods("GET")
+	v2SubRouter.HandleFunc("/head-to-head", we.coreWrapFunc(we.apis.GetHeadToHead)).Methods("GET")
+	v2SubRouter.HandleFunc("/standings", we.coreWrapFunc(we.apis.GetStandings)).Methods("GET")
 	v2SubRouter.HandleFunc("/live-games", we.coreWrapFunc(we.apis.GetLiveGames)).Methods("GET")
+	v2SubRouter.HandleFunc("/games/{id}/boxscore", we.coreWrapFunc(we.apis.GetGameBoxScore)).Methods("GET")
+	v2SubRouter.HandleFunc("/games/{id}/plays", we.coreWrapFunc(we.apis.GetGamePlays)).Methods("GET")
+	// the feeds are read by the feed readers and the calendar apps, which cannot authenticate
+	v2SubRouter.HandleFunc("/feeds/news.rss", we.publicWrapFunc(we.apis.GetNewsRSS)).Methods("GET")
+	v2SubRouter.HandleFunc("/feeds/news.atom", we.publicWrapFunc(we.apis.GetNewsAtom)).Methods("GET")
+	v2SubRouter.HandleFunc("/feeds/schedule.ics", we.publicWrapFunc(we.apis.GetScheduleCalendar)).Methods("GET")
 	//////////////////////////////////////////////////
 	/// BBs APIs
 	bbsSubRouter := apiSubRouter.PathPrefix("/bbs").Subrouter()
@@ -68,6 +83,14 @@ func (we Adapter) Start() {
 	}
 }
 
+func (we Adapter) publicWrapFunc(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logRequest(r)
+
+		handler(w, r)
+	}
+}
+
 func (we Adapter) coreWrapFunc(handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		logRequest(r)
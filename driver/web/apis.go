@@ -15,14 +15,18 @@
 package web
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"regexp"
 	"sport/core"
+	"sport/core/model"
 	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
 )
 
 // ApisHandler structure
@@ -56,15 +60,15 @@ func (a *ApisHandler) GetNews(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sports := r.URL.Query()["sport"]
-	limit, el := parseLimit(r)
-	if el != nil {
-		log.Println(el.Error())
-		http.Error(w, el.Error(), http.StatusBadRequest)
+	filter, err := parseNewsFilter(r)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	filter.ID = id
 
-	news, err := a.app.GetNews(id, sports, limit)
+	page, err := a.app.GetNews(*filter)
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to retrieve news. Reason: %s", err.Error())
 		log.Println(errMsg)
@@ -72,12 +76,19 @@ func (a *ApisHandler) GetNews(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(news) == 0 {
+	if page.TotalExact {
+		w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	}
+	if page.Next != nil {
+		w.Header().Set("X-Next-Cursor", encodeNewsCursor(*page.Next))
+	}
+
+	if len(page.News) == 0 {
 		successfulResponse(w, []byte("[]"))
 		return
 	}
 
-	newsJSON, err := json.Marshal(news)
+	newsJSON, err := json.Marshal(page.News)
 	if err != nil {
 		errMsg := "Failed to parse news to json."
 		log.Printf("%s Reason: %s", errMsg, err.Error())
@@ -96,7 +107,13 @@ func (a *ApisHandler) GetCoaches(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	coaches, err := a.app.GetCoaches(*sport)
+	year, err := parseYear(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	coaches, err := a.app.GetCoaches(*sport, year)
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to retrieve coaches. Reason: %s", err.Error())
 		log.Println(errMsg)
@@ -128,7 +145,20 @@ func (a *ApisHandler) GetPlayers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	players, err := a.app.GetPlayers(*sport)
+	year, err := parseYear(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parsePlayersFilter(r)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	players, err := a.app.GetPlayers(*sport, year, *filter)
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to retrieve players. Reason: %s", err.Error())
 		log.Println(errMsg)
@@ -152,6 +182,86 @@ func (a *ApisHandler) GetPlayers(w http.ResponseWriter, r *http.Request) {
 	successfulResponse(w, []byte(playersJSON))
 }
 
+// GetPlayer retrieves the full profile of a player
+func (a *ApisHandler) GetPlayer(w http.ResponseWriter, r *http.Request) {
+	sport, err := parseSport(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	year, err := parseYear(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	player, err := a.app.GetPlayer(*sport, year, id)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve player. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	if player == nil {
+		errMsg := fmt.Sprintf("There is no player with id [%s]", id)
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusNotFound)
+		return
+	}
+
+	playerJSON, err := json.Marshal(player)
+	if err != nil {
+		errMsg := "Failed to parse player to json."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	successfulResponse(w, []byte(playerJSON))
+}
+
+// GetCoach retrieves the full profile of a coach
+func (a *ApisHandler) GetCoach(w http.ResponseWriter, r *http.Request) {
+	sport, err := parseSport(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	year, err := parseYear(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	coach, err := a.app.GetCoach(*sport, year, id)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve coach. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	if coach == nil {
+		errMsg := fmt.Sprintf("There is no coach with id [%s]", id)
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusNotFound)
+		return
+	}
+
+	coachJSON, err := json.Marshal(coach)
+	if err != nil {
+		errMsg := "Failed to parse coach to json."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	successfulResponse(w, []byte(coachJSON))
+}
+
 // GetSocialNetworks retrieves social networks
 func (a *ApisHandler) GetSocialNetworks(w http.ResponseWriter, r *http.Request) {
 	socNets, err := a.app.GetSocialNetworks()
@@ -188,14 +298,29 @@ func (a *ApisHandler) GetGames(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startDate, err := parseDate("start", r)
+	location := getChicagoLocation()
+	start, err := parseTime("start", r, false, location)
 	if err != nil {
 		log.Println(err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	endDate, err := parseDate("end", r)
+	end, err := parseTime("end", r, true, location)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if start != nil && end != nil && !start.Before(*end) {
+		errMsg := "'start' must be before 'end'"
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	past, err := parseBool("past", r)
 	if err != nil {
 		log.Println(err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -209,7 +334,8 @@ func (a *ApisHandler) GetGames(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	games, err := a.app.GetGames(sports, id, startDate, endDate, limit)
+	filter := model.GamesFilter{Sports: sports, ID: id, Start: start, End: end, Past: past, Limit: limit}
+	games, err := a.app.GetGames(filter)
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to retrieve games. Reason: %s", err.Error())
 		log.Println(errMsg)
@@ -233,6 +359,72 @@ func (a *ApisHandler) GetGames(w http.ResponseWriter, r *http.Request) {
 	successfulResponse(w, []byte(gamesJSON))
 }
 
+// GetNewsRSS retrieves the news as RSS feed
+func (a *ApisHandler) GetNewsRSS(w http.ResponseWriter, r *http.Request) {
+	a.getNewsFeed(w, r, "application/rss+xml; charset=utf-8", buildNewsRSS)
+}
+
+// GetNewsAtom retrieves the news as Atom feed
+func (a *ApisHandler) GetNewsAtom(w http.ResponseWriter, r *http.Request) {
+	a.getNewsFeed(w, r, "application/atom+xml; charset=utf-8", buildNewsAtom)
+}
+
+func (a *ApisHandler) getNewsFeed(w http.ResponseWriter, r *http.Request, contentType string, build func([]model.News, string) ([]byte, error)) {
+	filter, err := parseNewsFilter(r)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultFeedLimit
+	}
+	filter.Format = model.NewsFormatHTML
+
+	page, err := a.app.GetNews(*filter)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve news. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	feed, err := build(page.News, getRequestURL(r))
+	if err != nil {
+		errMsg := "Failed to build news feed."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	feedResponse(w, contentType, feed)
+}
+
+// GetScheduleCalendar retrieves schedule for a team/sport as iCalendar
+func (a *ApisHandler) GetScheduleCalendar(w http.ResponseWriter, r *http.Request) {
+	sport, err := parseSport(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	year, err := parseYear(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := a.app.GetTeamSchedule(*sport, year)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve team schedule. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	feedResponse(w, "text/calendar; charset=utf-8", buildScheduleCalendar(*schedule, *sport, time.Now()))
+}
+
 // GetTeamSchedule retrieves schedule for a team/sport
 func (a *ApisHandler) GetTeamSchedule(w http.ResponseWriter, r *http.Request) {
 	sport, err := parseSport(r)
@@ -299,6 +491,120 @@ func (a *ApisHandler) GetTeamRecord(w http.ResponseWriter, r *http.Request) {
 	successfulResponse(w, []byte(recordJSON))
 }
 
+// GetTeamHistory retrieves all seasons of a team/sport with their records
+func (a *ApisHandler) GetTeamHistory(w http.ResponseWriter, r *http.Request) {
+	sport, err := parseSport(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	seasons, err := a.app.GetTeamHistory(*sport)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve team history. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	if len(seasons) == 0 {
+		successfulResponse(w, []byte("[]"))
+		return
+	}
+
+	seasonsJSON, err := json.Marshal(seasons)
+	if err != nil {
+		errMsg := "Failed to parse team history to json."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	successfulResponse(w, []byte(seasonsJSON))
+}
+
+// GetHeadToHead retrieves the summary of the games of a team/sport against an opponent
+func (a *ApisHandler) GetHeadToHead(w http.ResponseWriter, r *http.Request) {
+	sport, err := parseSport(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opponents := r.URL.Query()["opponent"]
+	if len(opponents) != 1 {
+		errMsg := "please provide exactly one 'opponent' query parameter"
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+	opponentID, err := strconv.Atoi(opponents[0])
+	if err != nil || opponentID <= 0 {
+		errMsg := fmt.Sprintf("'opponent' parameter must be the global id of the opponent - current is [%s]", opponents[0])
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	limit, err := parseLimit(r)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	headToHead, err := a.app.GetHeadToHead(*sport, opponentID, limit)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve head to head. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	headToHeadJSON, err := json.Marshal(headToHead)
+	if err != nil {
+		errMsg := "Failed to parse head to head to json."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	successfulResponse(w, []byte(headToHeadJSON))
+}
+
+// GetStandings retrieves the conference standings of a team/sport
+func (a *ApisHandler) GetStandings(w http.ResponseWriter, r *http.Request) {
+	sport, err := parseSport(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	standings, err := a.app.GetStandings(*sport)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve standings. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	if standings == nil {
+		errMsg := fmt.Sprintf("There are no standings for sport [%s], the standings source is not configured", *sport)
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusNotFound)
+		return
+	}
+
+	standingsJSON, err := json.Marshal(standings)
+	if err != nil {
+		errMsg := "Failed to parse standings to json."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	successfulResponse(w, []byte(standingsJSON))
+}
+
 // GetLiveGames retrieves current live games
 func (a *ApisHandler) GetLiveGames(w http.ResponseWriter, r *http.Request) {
 	liveGames, err := a.app.GetLiveGames()
@@ -331,6 +637,78 @@ func (a *ApisHandler) GetLiveGames(w http.ResponseWriter, r *http.Request) {
 	successfulResponse(w, []byte(result))
 }
 
+// GetGameBoxScore retrieves the live or final box score of a game
+func (a *ApisHandler) GetGameBoxScore(w http.ResponseWriter, r *http.Request) {
+	gameID, err := parseGameID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	boxScore, err := a.app.GetBoxScore(gameID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve box score. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	if boxScore == nil {
+		errMsg := fmt.Sprintf("There is no box score for game [%d]", gameID)
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusNotFound)
+		return
+	}
+
+	boxScoreJSON, err := json.Marshal(boxScore)
+	if err != nil {
+		errMsg := "Failed to parse box score to json."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	successfulResponse(w, []byte(boxScoreJSON))
+}
+
+// GetGamePlays retrieves the play by play of a game, only the plays after the since sequence number if it is provided
+func (a *ApisHandler) GetGamePlays(w http.ResponseWriter, r *http.Request) {
+	gameID, err := parseGameID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	since, err := parseSince(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plays, err := a.app.GetPlays(gameID, since)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve plays. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	if plays == nil {
+		errMsg := fmt.Sprintf("There are no plays for game [%d]", gameID)
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusNotFound)
+		return
+	}
+
+	playsJSON, err := json.Marshal(plays)
+	if err != nil {
+		errMsg := "Failed to parse plays to json."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	successfulResponse(w, []byte(playsJSON))
+}
+
 // GetConfig retrieves the configs
 func (a *ApisHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
 	config, err := a.app.GetConfig()
@@ -364,7 +742,7 @@ func (a *ApisHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 
 	err = a.app.UpdateConfig(cfgBytes)
 	if err != nil {
-		errMsg := "failed to update config"
+		errMsg := fmt.Sprintf("failed to update config, reason: %s", err.Error())
 		log.Printf("apis -> updateConfig: failed, reason: %s", err.Error())
 		http.Error(w, errMsg, http.StatusInternalServerError)
 		return
@@ -373,6 +751,105 @@ func (a *ApisHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 	successfulResponse(w, []byte("Successfully updated"))
 }
 
+// GetNotificationsOutbox retrieves the pending and the dead-lettered notifications
+func (a *ApisHandler) GetNotificationsOutbox(w http.ResponseWriter, r *http.Request) {
+	outbox, err := a.app.GetNotificationsOutbox()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve notifications outbox. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	result, err := json.Marshal(outbox)
+	if err != nil {
+		errMsg := "Failed to parse notifications outbox to json."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	successfulResponse(w, []byte(result))
+}
+
+// GetSourceHealthEvents retrieves the latest health events of the live stats sources
+func (a *ApisHandler) GetSourceHealthEvents(w http.ResponseWriter, r *http.Request) {
+	events, err := a.app.GetSourceHealthEvents()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve source health events. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	result, err := json.Marshal(events)
+	if err != nil {
+		errMsg := "Failed to parse source health events to json."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	successfulResponse(w, []byte(result))
+}
+
+// ReplayNotifications sends again the dead-lettered notification with the given id or all of them
+func (a *ApisHandler) ReplayNotifications(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to replay notifications. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	replayed, err := a.app.ReplayNotifications(id)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to replay notifications. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+	if id != nil && replayed == 0 {
+		errMsg := fmt.Sprintf("Failed to replay notifications. Reason: no dead letter with id %s", *id)
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusNotFound)
+		return
+	}
+
+	successfulResponse(w, []byte(fmt.Sprintf("Successfully replayed %d notifications", replayed)))
+}
+
+// PreviewNotification renders a notification message template against the sample data or a game
+func (a *ApisHandler) PreviewNotification(w http.ResponseWriter, r *http.Request) {
+	var preview model.NotificationPreview
+	err := json.NewDecoder(r.Body).Decode(&preview)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to preview notification. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	text, err := a.app.PreviewNotification(preview)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to preview notification. Reason: %s", err.Error())
+		log.Println(errMsg)
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	result, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		errMsg := "Failed to parse notification preview to json."
+		log.Printf("%s Reason: %s", errMsg, err.Error())
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	successfulResponse(w, []byte(result))
+}
+
 func parseID(r *http.Request) (*string, error) {
 	ids := r.URL.Query()["id"]
 	idsCount := len(ids)
@@ -387,6 +864,33 @@ func parseID(r *http.Request) (*string, error) {
 	return id, nil
 }
 
+func parseGameID(r *http.Request) (int, error) {
+	id := mux.Vars(r)["id"]
+	gameID, err := strconv.Atoi(id)
+	if err != nil || gameID <= 0 {
+		return 0, fmt.Errorf("game id must be positive number - current is [%s]", id)
+	}
+	return gameID, nil
+}
+
+func parseSince(r *http.Request) (int, error) {
+	sinces := r.URL.Query()["since"]
+	sincesCount := len(sinces)
+	if sincesCount > 1 {
+		return 0, fmt.Errorf("'since' query parameter's number must be max 1 - current is [%d]", sincesCount)
+	}
+
+	var since int
+	if sincesCount == 1 {
+		val, sinceErr := strconv.Atoi(sinces[0])
+		if sinceErr != nil || val < 0 {
+			return 0, fmt.Errorf("'since' parameter must be positive number - current is [%s]", sinces[0])
+		}
+		since = val
+	}
+	return since, nil
+}
+
 func parseLimit(r *http.Request) (int, error) {
 	limits := r.URL.Query()["limit"]
 	limitsCount := len(limits)
@@ -405,19 +909,159 @@ func parseLimit(r *http.Request) (int, error) {
 	return limit, nil
 }
 
-func parseDate(key string, r *http.Request) (*string, error) {
-	dates := r.URL.Query()[key]
-	var dateVal *string
-	var err error
-	if dates != nil {
-		if len(dates) == 1 {
-			dateVal = &dates[0]
-			err = validateDate(dateVal)
-		} else {
-			err = fmt.Errorf("please provide just one 'end' query parameter")
+func parseNewsFilter(r *http.Request) (*model.NewsFilter, error) {
+	query := r.URL.Query()
+	limit, err := parseLimit(r)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := parseOffset(r)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := parseNewsCursor(r)
+	if err != nil {
+		return nil, err
+	}
+	if cursor != nil && offset > 0 {
+		return nil, fmt.Errorf("please provide either 'offset' or 'cursor' query parameter")
+	}
+	since, err := parseTime("since", r, false, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	until, err := parseTime("until", r, true, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	if len(query["q"]) > 1 {
+		return nil, fmt.Errorf("'q' query parameter's number must be max 1 - current is [%d]", len(query["q"]))
+	}
+	format := query.Get("format")
+	if len(format) > 0 && format != model.NewsFormatHTML && format != model.NewsFormatText && format != model.NewsFormatMarkdown {
+		return nil, fmt.Errorf("'format' parameter must be html, text or markdown - current is [%s]", format)
+	}
+
+	return &model.NewsFilter{Sports: query["sport"], Categories: query["category"], Query: query.Get("q"), Since: since, Until: until,
+		Offset: offset, Cursor: cursor, Limit: limit, Format: format}, nil
+}
+
+func parsePlayersFilter(r *http.Request) (*model.PlayersFilter, error) {
+	query := r.URL.Query()
+	for _, key := range []string{"position", "class", "number", "sort"} {
+		if len(query[key]) > 1 {
+			return nil, fmt.Errorf("'%s' query parameter's number must be max 1 - current is [%d]", key, len(query[key]))
+		}
+	}
+
+	sort := query.Get("sort")
+	if len(sort) > 0 && sort != model.PlayersSortName && sort != model.PlayersSortNumber && sort != model.PlayersSortPosition && sort != model.PlayersSortClass {
+		return nil, fmt.Errorf("'sort' parameter must be name, number, position or class - current is [%s]", sort)
+	}
+	return &model.PlayersFilter{Position: query.Get("position"), Class: query.Get("class"), Number: query.Get("number"), Sort: sort}, nil
+}
+
+func parseOffset(r *http.Request) (int, error) {
+	offsets := r.URL.Query()["offset"]
+	offsetsCount := len(offsets)
+	if offsetsCount > 1 {
+		return 0, fmt.Errorf("'offset' query parameter's number must be max 1 - current is [%d]", offsetsCount)
+	}
+
+	var offset int
+	if offsetsCount == 1 {
+		val, offsetErr := strconv.Atoi(offsets[0])
+		if offsetErr != nil || val < 0 {
+			return 0, fmt.Errorf("'offset' parameter must be positive number - current is [%s]", offsets[0])
+		}
+		offset = val
+	}
+	return offset, nil
+}
+
+func parseNewsCursor(r *http.Request) (*model.NewsCursor, error) {
+	cursors := r.URL.Query()["cursor"]
+	if len(cursors) == 0 {
+		return nil, nil
+	}
+	if len(cursors) > 1 {
+		return nil, fmt.Errorf("'cursor' query parameter's number must be max 1 - current is [%d]", len(cursors))
+	}
+
+	var cursor model.NewsCursor
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(cursors[0])
+	if err == nil {
+		err = json.Unmarshal(cursorBytes, &cursor)
+	}
+	if err != nil || len(cursor.ID) == 0 {
+		return nil, fmt.Errorf("invalid 'cursor' parameter [%s]", cursors[0])
+	}
+	return &cursor, nil
+}
+
+func encodeNewsCursor(cursor model.NewsCursor) string {
+	cursorBytes, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(cursorBytes)
+}
+
+// parseTime parses ISO 8601 time with or without time zone, ISO 8601 date or the legacy MM/dd/yyyy date. The times without time zone
+// and the dates are in the location. The parsed time is an exclusive end if endOfDay is true: a date is the start of the next day,
+// so the whole day is included, while a date time is used as given, so the time itself is not included.
+func parseTime(key string, r *http.Request, endOfDay bool, location *time.Location) (*time.Time, error) {
+	values := r.URL.Query()[key]
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if len(values) > 1 {
+		return nil, fmt.Errorf("'%s' query parameter's number must be max 1 - current is [%d]", key, len(values))
+	}
+
+	value := values[0]
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return &t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		t, err = time.ParseInLocation(layout, value, location)
+		if err == nil {
+			return &t, nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02", "01/02/2006"} {
+		t, err = time.ParseInLocation(layout, value, location)
+		if err == nil {
+			if endOfDay {
+				t = t.AddDate(0, 0, 1)
+			}
+			return &t, nil
 		}
 	}
-	return dateVal, err
+	return nil, fmt.Errorf("provide valid '%s' in format 'yyyy-MM-dd', 'MM/dd/yyyy' or ISO 8601 date time - current is [%s]", key, value)
+}
+
+func parseBool(key string, r *http.Request) (bool, error) {
+	values := r.URL.Query()[key]
+	if len(values) == 0 {
+		return false, nil
+	}
+	if len(values) > 1 {
+		return false, fmt.Errorf("'%s' query parameter's number must be max 1 - current is [%d]", key, len(values))
+	}
+	value, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return false, fmt.Errorf("'%s' parameter must be true or false - current is [%s]", key, values[0])
+	}
+	return value, nil
+}
+
+// getChicagoLocation gives the time zone of the games, the dates without time zone are in it
+func getChicagoLocation() *time.Location {
+	location, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		log.Printf("failed to load Chicago location. Reason: %s", err.Error())
+		return time.UTC
+	}
+	return location
 }
 
 func parseSport(r *http.Request) (*string, error) {
@@ -449,19 +1093,6 @@ func parseYear(r *http.Request) (*int, error) {
 	return y, nil
 }
 
-func validateDate(date *string) error {
-	if date == nil {
-		return nil
-	}
-	// MM/dd/yyyy
-	re := regexp.MustCompile("(0[1-9]|1[0-2])/(0[1-9]|1[0-9]|2[0-9]|3[0-1])/(1[89]|2[0-9])[0-9][0-9]")
-	valid := re.MatchString(*date)
-	if valid {
-		return nil
-	}
-	return fmt.Errorf("provide valid date in format 'MM/dd/yyyy'")
-}
-
 func successfulResponse(w http.ResponseWriter, responseBytes []byte) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
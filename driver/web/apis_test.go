@@ -0,0 +1,140 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("the time zone database is not available")
+	}
+	tests := []struct {
+		name     string
+		value    string
+		endOfDay bool
+		expected time.Time
+	}{
+		{"RFC 3339", "2023-10-21T14:30:00Z", false, time.Date(2023, 10, 21, 14, 30, 0, 0, time.UTC)},
+		{"RFC 3339 end is used as given", "2023-10-21T14:30:00-05:00", true, time.Date(2023, 10, 21, 19, 30, 0, 0, time.UTC)},
+		{"local date time", "2023-10-21T14:30:00", false, time.Date(2023, 10, 21, 14, 30, 0, 0, chicago)},
+		{"local date time end is used as given", "2023-10-21T14:30:00", true, time.Date(2023, 10, 21, 14, 30, 0, 0, chicago)},
+		{"local date time without seconds", "2023-10-21T14:30", false, time.Date(2023, 10, 21, 14, 30, 0, 0, chicago)},
+		{"date", "2023-10-21", false, time.Date(2023, 10, 21, 0, 0, 0, 0, chicago)},
+		{"date end is the next day", "2023-10-21", true, time.Date(2023, 10, 22, 0, 0, 0, 0, chicago)},
+		{"legacy date", "10/21/2023", false, time.Date(2023, 10, 21, 0, 0, 0, 0, chicago)},
+		{"legacy date end is the next day", "10/21/2023", true, time.Date(2023, 10, 22, 0, 0, 0, 0, chicago)},
+		{"end of the year", "2023-12-31", true, time.Date(2024, 1, 1, 0, 0, 0, 0, chicago)},
+		{"date before the spring DST change", "2024-03-09", true, time.Date(2024, 3, 10, 0, 0, 0, 0, chicago)},
+		{"date of the spring DST change", "2024-03-10", true, time.Date(2024, 3, 11, 0, 0, 0, 0, chicago)},
+		{"date of the fall DST change", "2024-11-03", true, time.Date(2024, 11, 4, 0, 0, 0, 0, chicago)},
+		{"local date time after the spring DST change", "2024-03-10T03:30:00", false, time.Date(2024, 3, 10, 8, 30, 0, 0, time.UTC)},
+		{"local date time before the fall DST change", "2024-11-03T00:30:00", false, time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC)},
+		{"local date time after the fall DST change", "2024-11-03T03:30:00", false, time.Date(2024, 11, 3, 9, 30, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/games?end="+url.QueryEscape(test.value), nil)
+			parsed, err := parseTime("end", r, test.endOfDay, chicago)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed == nil || !parsed.Equal(test.expected) {
+				t.Errorf("parsed %v, expected %v", parsed, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseTimeDayLength(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("the time zone database is not available")
+	}
+	//the whole local day is included even if it is shorter or longer because of the DST change
+	tests := []struct {
+		date     string
+		expected time.Duration
+	}{
+		{"2024-03-10", 23 * time.Hour},
+		{"2024-07-04", 24 * time.Hour},
+		{"2024-11-03", 25 * time.Hour},
+	}
+	for _, test := range tests {
+		t.Run(test.date, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/games?start="+test.date+"&end="+test.date, nil)
+			start, _ := parseTime("start", r, false, chicago)
+			end, _ := parseTime("end", r, true, chicago)
+			if length := end.Sub(*start); length != test.expected {
+				t.Errorf("the day is %s long, expected %s", length, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseTimeInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown layout", "end=21.10.2023"},
+		{"invalid date", "end=2023-02-30"},
+		{"time without date", "end=14:30"},
+		{"more values", "end=2023-10-21&end=2023-10-22"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/games?"+test.query, nil)
+			if parsed, err := parseTime("end", r, true, time.UTC); err == nil {
+				t.Errorf("parsed %v, expected an error", parsed)
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/games", nil)
+	if parsed, err := parseTime("end", r, true, time.UTC); parsed != nil || err != nil {
+		t.Errorf("parsed %v error %v for the missing parameter", parsed, err)
+	}
+}
+
+func TestGetGamesRejectsStartNotBeforeEnd(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"same date time", "start=2023-10-21T14:30:00&end=2023-10-21T14:30:00"},
+		{"end before start", "start=2023-10-22&end=2023-10-21"},
+		{"end date time before start", "start=2023-10-21T18:00:00Z&end=2023-10-21T12:00:00-05:00"},
+		{"end at the start of the day", "start=2023-10-21&end=2023-10-21T00:00:00"},
+		{"invalid start", "start=yesterday"},
+		{"invalid end", "end=tomorrow"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := &ApisHandler{}
+			w := httptest.NewRecorder()
+			handler.GetGames(w, httptest.NewRequest(http.MethodGet, "/games?"+test.query, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status %d, expected %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
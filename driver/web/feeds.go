@@ -0,0 +1,344 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sport/core/model"
+	"strings"
+	"time"
+)
+
+const (
+	feedTitle       string = "Illinois Athletics News"
+	feedDescription string = "The latest news of the Fighting Illini"
+	calendarProdID  string = "-//Rokwire//Sports Service//EN"
+	// the count of the news in the feeds when there is no limit
+	defaultFeedLimit = 20
+	// the games without end time are shown for this long in the calendar
+	defaultGameDuration = 3 * time.Hour
+)
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Content string     `xml:"xmlns:content,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description,omitempty"`
+	Content     string  `xml:"content:encoded,omitempty"`
+	Category    string  `xml:"category,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID       string        `xml:"id"`
+	Title    string        `xml:"title"`
+	Updated  string        `xml:"updated"`
+	Link     *atomLink     `xml:"link,omitempty"`
+	Category *atomCategory `xml:"category,omitempty"`
+	Summary  *atomText     `xml:"summary,omitempty"`
+	Content  *atomText     `xml:"content,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// buildNewsRSS builds RSS 2.0 feed of the news
+func buildNewsRSS(news []model.News, selfURL string) ([]byte, error) {
+	channel := rssChannel{Title: feedTitle, Link: selfURL, Description: feedDescription,
+		AtomLink: atomLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"}}
+	var lastBuild time.Time
+	for _, item := range news {
+		rssItem := rssItem{Title: item.Title, Link: item.Link, Description: getNewsSummary(item), Content: item.FullText,
+			Category: item.Category, GUID: rssGUID{IsPermaLink: "false", Value: item.ID}}
+		if pubDate, err := model.ParseNewsTime(item.PubDateUtc); err == nil {
+			rssItem.PubDate = pubDate.Format(time.RFC1123Z)
+			if pubDate.After(lastBuild) {
+				lastBuild = pubDate
+			}
+		}
+		channel.Items = append(channel.Items, rssItem)
+	}
+	if !lastBuild.IsZero() {
+		channel.LastBuildDate = lastBuild.Format(time.RFC1123Z)
+	}
+
+	feed := rss{Version: "2.0", Content: "http://purl.org/rss/1.0/modules/content/", Atom: "http://www.w3.org/2005/Atom", Channel: channel}
+	return marshalFeed(feed)
+}
+
+// buildNewsAtom builds Atom feed of the news
+func buildNewsAtom(news []model.News, selfURL string) ([]byte, error) {
+	feed := atomFeed{ID: selfURL, Title: feedTitle, Link: []atomLink{{Href: selfURL, Rel: "self", Type: "application/atom+xml"}}}
+	var updated time.Time
+	for _, item := range news {
+		entry := atomEntry{ID: fmt.Sprintf("%s#%s", selfURL, item.ID), Title: item.Title}
+		if len(item.Link) > 0 {
+			entry.ID = item.Link
+			entry.Link = &atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"}
+		}
+		if len(item.Category) > 0 {
+			entry.Category = &atomCategory{Term: item.Category}
+		}
+		if summary := getNewsSummary(item); len(summary) > 0 {
+			entry.Summary = &atomText{Type: "text", Value: summary}
+		}
+		if len(item.FullText) > 0 {
+			entry.Content = &atomText{Type: "html", Value: item.FullText}
+		}
+		pubDate, err := model.ParseNewsTime(item.PubDateUtc)
+		if err != nil {
+			pubDate = time.Now().UTC()
+		}
+		entry.Updated = pubDate.Format(time.RFC3339)
+		if pubDate.After(updated) {
+			updated = pubDate
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	if updated.IsZero() {
+		updated = time.Now().UTC()
+	}
+	feed.Updated = updated.Format(time.RFC3339)
+	return marshalFeed(feed)
+}
+
+func marshalFeed(feed interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func getNewsSummary(news model.News) string {
+	if len(news.Excerpt) > 0 {
+		return news.Excerpt
+	}
+	return news.Description
+}
+
+// buildScheduleCalendar builds iCalendar of the schedule
+func buildScheduleCalendar(schedule model.Schedule, sport string, now time.Time) []byte {
+	var b strings.Builder
+	writeCalendarLine(&b, "BEGIN", "VCALENDAR")
+	writeCalendarLine(&b, "VERSION", "2.0")
+	writeCalendarLine(&b, "PRODID", calendarProdID)
+	writeCalendarLine(&b, "CALSCALE", "GREGORIAN")
+	writeCalendarLine(&b, "METHOD", "PUBLISH")
+	writeCalendarLine(&b, "X-WR-CALNAME", escapeCalendarText(strings.TrimSpace(fmt.Sprintf("Illinois %s %s", getScheduleSportTitle(schedule, sport), schedule.Label))))
+	writeCalendarLine(&b, "X-WR-TIMEZONE", "America/Chicago")
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, game := range schedule.Games {
+		start, end, allDay, ok := getGameTimes(game)
+		if !ok {
+			continue
+		}
+		writeCalendarLine(&b, "BEGIN", "VEVENT")
+		writeCalendarLine(&b, "UID", fmt.Sprintf("game-%s-%s@sports-service", sport, game.ID))
+		writeCalendarLine(&b, "DTSTAMP", stamp)
+		if allDay {
+			writeCalendarLine(&b, "DTSTART;VALUE=DATE", start.Format("20060102"))
+			writeCalendarLine(&b, "DTEND;VALUE=DATE", end.Format("20060102"))
+		} else {
+			writeCalendarLine(&b, "DTSTART", start.Format("20060102T150405Z"))
+			writeCalendarLine(&b, "DTEND", end.Format("20060102T150405Z"))
+		}
+		writeCalendarLine(&b, "SUMMARY", escapeCalendarText(getGameSummary(game)))
+		if game.Location != nil && len(game.Location.Location) > 0 {
+			writeCalendarLine(&b, "LOCATION", escapeCalendarText(game.Location.Location))
+		}
+		if description := getGameDescription(game); len(description) > 0 {
+			writeCalendarLine(&b, "DESCRIPTION", escapeCalendarText(description))
+		}
+		if game.Links != nil && len(game.Links.Tickets) > 0 {
+			writeCalendarLine(&b, "URL", game.Links.Tickets)
+		}
+		switch game.Status {
+		case "C":
+			writeCalendarLine(&b, "STATUS", "CANCELLED")
+		case "P":
+			writeCalendarLine(&b, "STATUS", "TENTATIVE")
+		default:
+			writeCalendarLine(&b, "STATUS", "CONFIRMED")
+		}
+		writeCalendarLine(&b, "END", "VEVENT")
+	}
+	writeCalendarLine(&b, "END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// getGameTimes gives the start and the end of the game. The all day games have dates only and the end is exclusive.
+func getGameTimes(game model.Game) (time.Time, time.Time, bool, bool) {
+	if game.AllDay {
+		start, err := time.Parse("2006-01-02", firstChars(game.Date, 10))
+		if err != nil {
+			return time.Time{}, time.Time{}, false, false
+		}
+		end, err := time.Parse("2006-01-02", firstChars(game.EndDate, 10))
+		if err != nil || end.Before(start) {
+			end = start
+		}
+		return start, end.AddDate(0, 0, 1), true, true
+	}
+
+	start, err := time.Parse(time.RFC3339, game.DateTimeUtc)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, false
+	}
+	end, err := time.Parse(time.RFC3339, game.EndDateTimeUtc)
+	if err != nil || !end.After(start) {
+		end = start.Add(defaultGameDuration)
+	}
+	return start.UTC(), end.UTC(), false, true
+}
+
+func getGameSummary(game model.Game) string {
+	if len(game.Name) > 0 {
+		return game.Name
+	}
+	if game.Opponent != nil && len(game.Opponent.Name) > 0 {
+		return "Illinois vs " + game.Opponent.Name
+	}
+	return "Illinois"
+}
+
+func getGameDescription(game model.Game) string {
+	var lines []string
+	if game.Opponent != nil && len(game.Opponent.Name) > 0 {
+		lines = append(lines, "Opponent: "+game.Opponent.Name)
+	}
+	if len(game.Description) > 0 {
+		lines = append(lines, game.Description)
+	}
+	if game.Links != nil {
+		if len(game.Links.Tickets) > 0 {
+			lines = append(lines, "Tickets: "+game.Links.Tickets)
+		}
+		if len(game.Links.Video) > 0 {
+			lines = append(lines, "Video: "+game.Links.Video)
+		}
+		if len(game.Links.Audio) > 0 {
+			lines = append(lines, "Audio: "+game.Links.Audio)
+		}
+	}
+	if game.ParkingURL != nil {
+		lines = append(lines, "Parking: "+*game.ParkingURL)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func getScheduleSportTitle(schedule model.Schedule, sport string) string {
+	for _, game := range schedule.Games {
+		if game.Sport != nil && len(game.Sport.Title) > 0 {
+			return game.Sport.Title
+		}
+	}
+	return sport
+}
+
+var calendarEscaper = strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n")
+
+func escapeCalendarText(value string) string {
+	return calendarEscaper.Replace(value)
+}
+
+// writeCalendarLine writes the content line folded to 75 octets as RFC 5545 requires
+func writeCalendarLine(b *strings.Builder, name string, value string) {
+	line := name + ":" + value
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		//do not split the utf-8 characters
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		//the leading space of the continuation counts too
+		limit = 74
+	}
+	b.WriteString(line + "\r\n")
+}
+
+func firstChars(value string, count int) string {
+	if len(value) < count {
+		return value
+	}
+	return value[:count]
+}
+
+// getRequestURL gives the absolute url of the request
+func getRequestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); len(proto) > 0 {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL.RequestURI())
+}
+
+func feedResponse(w http.ResponseWriter, contentType string, responseBytes []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+}
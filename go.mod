@@ -1,11 +1,23 @@
 module sport
 
-go 1.16
+go 1.18
 
 require (
-	github.com/golang/mock v1.6.0 // indirect
 	github.com/gorilla/mux v1.7.3
 	github.com/jlaffaye/ftp v0.0.0-20190828173736-6aaa91c7796e
 	github.com/rokwire/core-auth-library-go/v2 v2.0.1
+	golang.org/x/net v0.30.0
+)
+
+require (
+	github.com/Knetic/govaluate v3.0.1-0.20171022003610-9aa49832a739+incompatible // indirect
+	github.com/casbin/casbin/v2 v2.31.10 // indirect
+	github.com/go-playground/locales v0.13.0 // indirect
+	github.com/go-playground/universal-translator v0.17.0 // indirect
+	github.com/golang-jwt/jwt v3.2.1+incompatible // indirect
+	github.com/golang/mock v1.6.0 // indirect
+	github.com/leodido/go-urn v1.2.1 // indirect
 	github.com/stretchr/testify v1.7.0 // indirect
+	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
+	gopkg.in/go-playground/validator.v9 v9.31.0 // indirect
 )